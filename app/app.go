@@ -20,9 +20,11 @@ const (
 // Consists the supported services and proxy chains.
 //
 // Fields
+//   - Version of the app configuration format, see CurrentVersion
 //   - Services in the application
 //   - ProxyChains list of proxies that targets to the services
 type App struct {
+	Version     int                   `json:"version" yaml:"version"`
 	Services    []*service.Service    `json:"services" yaml:"services"`
 	ProxyChains []*service.ProxyChain `json:"proxy_chains" yaml:"proxy_chains"`
 	ids         []string              // all service, handler ids must be unique.
@@ -67,6 +69,8 @@ func (a *App) SetId(id string) bool {
 
 // RegisterId sets all ids of the all services and handlers.
 // If there are duplicate id, then throw an error with detail information.
+//
+// To find all problems of the app configuration at once, use Validate.
 func (a *App) RegisterId() error {
 	for _, s := range a.Services {
 		if a.IdExist(s.Id) {
@@ -79,7 +83,7 @@ func (a *App) RegisterId() error {
 				return fmt.Errorf("the '%s' id of handler in '%s' service is duplicate", h.Id, s.Id)
 			}
 
-			a.SetId(s.Id)
+			a.SetId(h.Id)
 		}
 	}
 
@@ -112,6 +116,9 @@ func (a *App) ServiceByUrl(url string) *service.Service {
 // SetEmptyFields sets empty value for nil fields.
 // If the developer crated App directly, some fields might be nil
 func (a *App) SetEmptyFields() {
+	if a.Version == 0 {
+		a.Version = CurrentVersion
+	}
 	if a.Services == nil {
 		a.Services = make([]*service.Service, 0)
 	}
@@ -2,13 +2,16 @@ package app
 
 import (
 	"fmt"
+	clientConfig "github.com/ahmetson/client-lib/config"
 	"github.com/ahmetson/config-lib/engine"
 	"github.com/ahmetson/config-lib/service"
+	handlerConfig "github.com/ahmetson/handler-lib/config"
 	"github.com/ahmetson/log-lib"
 	"github.com/ahmetson/os-lib/path"
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/ahmetson/datatype-lib/data_type/key_value"
@@ -74,6 +77,13 @@ func (test *TestAppSuite) deleteYaml(dir string, name string) {
 
 	filePath := filepath.Join(dir, name+".yml")
 
+	// the lock file is created by Write
+	lockExist, err := path.FileExist(LockPath(filePath))
+	s().NoError(err)
+	if lockExist {
+		s().NoError(os.Remove(LockPath(filePath)))
+	}
+
 	exist, err := path.FileExist(filePath)
 	s().NoError(err)
 
@@ -84,6 +94,15 @@ func (test *TestAppSuite) deleteYaml(dir string, name string) {
 	s().NoError(os.Remove(filePath))
 }
 
+// registerMigration registers the migration for the test.
+// The migration is removed from the registry after the test.
+func (test *TestAppSuite) registerMigration(migration *Migration) {
+	test.Require().NoError(RegisterMigration(migration))
+	test.T().Cleanup(func() {
+		unregisterMigration(migration.From)
+	})
+}
+
 // Test_10_setDefault checks the setup of the default parameters
 func (test *TestAppSuite) Test_10_setDefault() {
 	s := test.Require
@@ -209,6 +228,458 @@ func (test *TestAppSuite) Test_13_read() {
 	test.deleteYaml(configPath, configName)
 }
 
+// Test_14_profile tests the selection of the profile configuration file
+func (test *TestAppSuite) Test_14_profile() {
+	s := test.Require
+
+	s().NoError(os.Setenv(engine.EnvProfile, "dev"))
+	dev, err := engine.NewDev()
+	s().NoError(err)
+	s().NoError(os.Unsetenv(engine.EnvProfile))
+
+	setDefault(test.execPath, dev)
+	test.createYaml(test.execPath, "app.dev")
+
+	// the profile file is preferred
+	params, exist, err := envExist(dev)
+	s().NoError(err)
+	s().True(exist)
+	loadedName, err := params.StringValue("name")
+	s().NoError(err)
+	s().Equal("app.dev", loadedName)
+
+	test.deleteYaml(test.execPath, "app.dev")
+
+	// without the profile file, the default file is used
+	params, exist, err = envExist(dev)
+	s().NoError(err)
+	s().False(exist)
+	loadedName, err = params.StringValue("name")
+	s().NoError(err)
+	s().Equal("app", loadedName)
+}
+
+// Test_15_write tests the atomic writing with the backups
+func (test *TestAppSuite) Test_15_write() {
+	s := test.Require
+
+	dir := test.T().TempDir()
+	filePath := filepath.Join(dir, "app.yml")
+
+	// there is nothing to back up
+	twoServices := New()
+	s().NoError(twoServices.SetService(service.New("first", "url_1", service.IndependentType, nil)))
+	s().NoError(twoServices.SetService(service.New("second", "url_2", service.IndependentType, nil)))
+	s().NoError(Write(filePath, twoServices))
+	backups, err := Backups(filePath)
+	s().NoError(err)
+	s().Empty(backups)
+
+	// the shorter file must not keep the bytes of the former file
+	oneService := New()
+	s().NoError(oneService.SetService(service.New("first", "url_1", service.IndependentType, nil)))
+	s().NoError(Write(filePath, oneService))
+
+	var loaded App
+	s().NoError(Read(filePath, &loaded))
+	s().Len(loaded.Services, 1)
+
+	backups, err = Backups(filePath)
+	s().NoError(err)
+	s().Equal([]string{BackupPath(filePath, 1)}, backups)
+
+	// the oldest backups are removed
+	for i := 0; i < MaxBackups+2; i++ {
+		s().NoError(Write(filePath, oneService))
+	}
+	backups, err = Backups(filePath)
+	s().NoError(err)
+	s().Len(backups, MaxBackups)
+
+	// no temporary files are left, only the file, the backups and the lock file
+	entries, err := os.ReadDir(dir)
+	s().NoError(err)
+	s().Len(entries, MaxBackups+2)
+
+	// restore the version with two services
+	s().NoError(Write(filePath, twoServices))
+	s().NoError(Write(filePath, oneService))
+	loaded = App{}
+	s().NoError(ReadBackup(filePath, 1, &loaded))
+	s().Len(loaded.Services, 2)
+	s().Error(ReadBackup(filePath, 0, &loaded))
+	s().NoError(Restore(filePath, 1))
+	loaded = App{}
+	s().NoError(Read(filePath, &loaded))
+	s().Len(loaded.Services, 2)
+
+	// the restoring could be undone
+	s().NoError(Restore(filePath, 1))
+	loaded = App{}
+	s().NoError(Read(filePath, &loaded))
+	s().Len(loaded.Services, 1)
+
+	s().Error(Restore(filePath, 0))
+	s().Error(Restore(filePath, MaxBackups+1))
+}
+
+// Test_16_update tests the merging of the concurrent updates
+func (test *TestAppSuite) Test_16_update() {
+	s := test.Require
+
+	dir := test.T().TempDir()
+	filePath := filepath.Join(dir, "app.yml")
+
+	// the reader doesn't create the lock file
+	readOnly := filepath.Join(dir, "read_only.yml")
+	s().NoError(os.WriteFile(readOnly, []byte("services: []\n"), 0400))
+	var readOnlyApp App
+	s().NoError(Read(readOnly, &readOnlyApp))
+	exist, err := path.FileExist(LockPath(readOnly))
+	s().NoError(err)
+	s().False(exist)
+
+	// the file is created by the first update
+	first, err := Update(filePath, nil, func(current *App) error {
+		return current.SetService(service.New("first", "url_1", service.IndependentType, nil))
+	})
+	s().NoError(err)
+	s().Len(first.Services, 1)
+	exist, err = path.FileExist(LockPath(filePath))
+	s().NoError(err)
+	s().True(exist)
+
+	// both processes read the same version
+	var base App
+	s().NoError(Read(filePath, &base))
+
+	// the other process changes the first service
+	_, err = Update(filePath, &base, func(current *App) error {
+		current.Service("first").SetParam("port", 8080)
+		return nil
+	})
+	s().NoError(err)
+
+	// the changes of the other services are merged
+	updated, err := Update(filePath, &base, func(current *App) error {
+		return current.SetService(service.New("second", "url_2", service.IndependentType, nil))
+	})
+	s().NoError(err)
+	s().Len(updated.Services, 2)
+	port, ok := updated.Service("first").Param("port")
+	s().True(ok)
+	s().Equal(8080, port)
+
+	// changing the same service from the stale base is the conflict
+	latest, err := Update(filePath, &base, func(current *App) error {
+		current.Service("first").SetParam("port", 9090)
+		return nil
+	})
+	s().Error(err)
+	conflict, ok := err.(*ConflictError)
+	s().True(ok)
+	s().Equal([]string{"first"}, conflict.Services)
+	s().NotNil(latest)
+	s().Len(latest.Services, 2)
+
+	// nothing was written
+	var loaded App
+	s().NoError(Read(filePath, &loaded))
+	port, _ = loaded.Service("first").Param("port")
+	s().Equal(8080, port)
+
+	// retrying from the latest version succeeds
+	_, err = Update(filePath, latest, func(current *App) error {
+		current.Service("first").SetParam("port", 9090)
+		return nil
+	})
+	s().NoError(err)
+	loaded = App{}
+	s().NoError(Read(filePath, &loaded))
+	port, _ = loaded.Service("first").Param("port")
+	s().Equal(9090, port)
+
+	// the failed update writes nothing
+	_, err = Update(filePath, latest, func(current *App) error {
+		return fmt.Errorf("failed")
+	})
+	s().Error(err)
+
+	// the proxy chains are compared as a whole
+	base = App{}
+	s().NoError(Read(filePath, &base))
+	addChain := func(url string) func(current *App) error {
+		return func(current *App) error {
+			current.ProxyChains = append(current.ProxyChains, &service.ProxyChain{
+				Proxies:     []*service.Proxy{},
+				Destination: &service.Rule{Urls: []string{url}},
+			})
+			return nil
+		}
+	}
+	_, err = Update(filePath, &base, addChain("url_1"))
+	s().NoError(err)
+	// the service changes don't conflict with the proxy chains
+	_, err = Update(filePath, &base, func(current *App) error {
+		current.Service("second").SetParam("port", 8080)
+		return nil
+	})
+	s().NoError(err)
+
+	latest, err = Update(filePath, &base, addChain("url_2"))
+	conflict, ok = err.(*ConflictError)
+	s().True(ok)
+	s().True(conflict.ProxyChains)
+	s().Empty(conflict.Services)
+	s().Len(latest.ProxyChains, 1)
+
+	updated, err = Update(filePath, latest, addChain("url_2"))
+	s().NoError(err)
+	s().Len(updated.ProxyChains, 2)
+}
+
+// Test_17_migrate tests the upgrading of the older app configuration
+func (test *TestAppSuite) Test_17_migrate() {
+	s := test.Require
+
+	dir := test.T().TempDir()
+	filePath := filepath.Join(dir, "app.yml")
+
+	// the file without the version is version 0
+	oldFormat := []byte("services:\n  - id: first\n    url: url_1\n    type: Independent\n")
+	s().NoError(os.WriteFile(filePath, oldFormat, 0600))
+
+	// the dry run doesn't change the file
+	report, err := MigrateFile(filePath, true)
+	s().NoError(err)
+	s().Equal(0, report.From)
+	s().Equal(CurrentVersion, report.To)
+	s().Len(report.Steps, CurrentVersion)
+	s().Contains(report.Changes, fmt.Sprintf("+ version: %d", CurrentVersion))
+	data, err := os.ReadFile(filePath)
+	s().NoError(err)
+	s().Equal(oldFormat, data)
+
+	// the older file is upgraded by reading
+	var loaded App
+	s().NoError(Read(filePath, &loaded))
+	s().Equal(CurrentVersion, loaded.Version)
+	s().Len(loaded.Services, 1)
+
+	// the upgraded file is written with the backup of the older file
+	report, err = MigrateFile(filePath, false)
+	s().NoError(err)
+	s().True(report.Changed())
+	backups, err := Backups(filePath)
+	s().NoError(err)
+	s().Len(backups, 1)
+
+	// nothing to upgrade
+	report, err = MigrateFile(filePath, false)
+	s().NoError(err)
+	s().False(report.Changed())
+	backups, err = Backups(filePath)
+	s().NoError(err)
+	s().Len(backups, 1)
+
+	// the newer version is not supported
+	s().NoError(os.WriteFile(filePath, []byte(fmt.Sprintf("version: %d\n", CurrentVersion+1)), 0600))
+	s().Error(Read(filePath, &App{}))
+
+	// the migrations are applied step by step
+	s().Error(RegisterMigration(&Migration{From: 0, Migrate: func(key_value.KeyValue) error { return nil }}))
+	test.registerMigration(&Migration{
+		From:        CurrentVersion,
+		Description: "rename url to address",
+		Migrate: func(doc key_value.KeyValue) error {
+			services, _ := doc["services"].([]interface{})
+			for _, raw := range services {
+				s := raw.(map[string]interface{})
+				s["address"] = s["url"]
+				delete(s, "url")
+			}
+			return nil
+		},
+	})
+
+	doc := key_value.New()
+	s().NoError(yaml.Unmarshal(oldFormat, &doc))
+	report, err = Migrate(doc, CurrentVersion+1)
+	s().NoError(err)
+	s().Len(report.Steps, CurrentVersion+1)
+	s().Contains(report.Changes, "+ services[0].address: url_1")
+	s().Contains(report.Changes, "- services[0].url: url_1")
+	version, err := DocVersion(doc)
+	s().NoError(err)
+	s().Equal(CurrentVersion+1, version)
+
+	// the migration is missing
+	_, err = Migrate(key_value.New(), CurrentVersion+2)
+	s().Error(err)
+
+	// the migrations are registered while the files are read, run with -race
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(2)
+		from := CurrentVersion + 10 + i
+		test.T().Cleanup(func() {
+			unregisterMigration(from)
+		})
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = RegisterMigration(&Migration{From: from, Migrate: func(key_value.KeyValue) error { return nil }})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _ = Migrate(key_value.New(), CurrentVersion)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		s().NoError(err)
+	}
+}
+
+// Test_18_formats tests the json and toml app configuration files
+func (test *TestAppSuite) Test_18_formats() {
+	s := test.Require
+
+	dir := test.T().TempDir()
+
+	manager, err := service.NewManager("first", "url_1")
+	s().NoError(err)
+	written := New()
+	s().NoError(written.SetService(service.New("first", "url_1", service.IndependentType, manager)))
+	written.Service("first").SetParam("port", 8080)
+
+	for _, ext := range Extensions {
+		filePath := filepath.Join(dir, "app"+ext)
+		s().NoError(Write(filePath, written))
+
+		var loaded App
+		s().NoError(Read(filePath, &loaded))
+		s().Equal(CurrentVersion, loaded.Version)
+		s().Len(loaded.Services, 1)
+		s().Equal("url_1", loaded.Services[0].Url)
+		s().Equal(service.IndependentType, loaded.Services[0].Type)
+		s().NotNil(loaded.Services[0].Manager)
+		port, ok := loaded.Service("first").Param("port")
+		s().True(ok)
+		s().EqualValues(8080, port)
+	}
+
+	// the json and toml files use the json tags
+	data, err := os.ReadFile(filepath.Join(dir, "app.toml"))
+	s().NoError(err)
+	s().Contains(string(data), "[[services]]")
+	s().Contains(string(data), "proxy_chains")
+
+	// the unsupported extension
+	s().Error(Write(filepath.Join(dir, "app.ini"), written))
+
+	// the configuration name with the extension
+	test.engine.Set(EnvConfigName, "app.json")
+	test.engine.Set(EnvConfigPath, dir)
+	params, exist, err := envExist(test.engine)
+	s().NoError(err)
+	s().True(exist)
+	s().Equal(filepath.Join(dir, "app.json"), fileParamsToPath(params))
+
+	// the configuration name without the extension is searched in the order of the extensions
+	s().NoError(os.Remove(filepath.Join(dir, "app.yml")))
+	s().NoError(os.Remove(filepath.Join(dir, "app.yaml")))
+	test.engine.Set(EnvConfigName, "app")
+	params, exist, err = envExist(test.engine)
+	s().NoError(err)
+	s().True(exist)
+	s().Equal(filepath.Join(dir, "app.json"), fileParamsToPath(params))
+
+	// the new file is the yaml
+	test.engine.Set(EnvConfigName, "new")
+	params, exist, err = envExist(test.engine)
+	s().NoError(err)
+	s().False(exist)
+	s().Equal(filepath.Join(dir, "new.yml"), fileParamsToPath(params))
+
+	// the flag accepts any supported extension
+	os.Args = append(os.Args, fmt.Sprintf(`--config=%s`, filepath.Join(dir, "app.toml")))
+	params, exist, err = flagExist(test.execPath)
+	s().NoError(err)
+	s().True(exist)
+	s().Equal(filepath.Join(dir, "app.toml"), fileParamsToPath(params))
+	os.Args = os.Args[:len(os.Args)-1]
+}
+
+// Test_19_validate tests the validation of the whole app configuration
+func (test *TestAppSuite) Test_19_validate() {
+	s := test.Require
+
+	proxy := &service.Proxy{Id: "proxy", Url: "url_proxy", Category: "authr"}
+
+	manager, err := service.NewManager("first", "url_1")
+	s().NoError(err)
+	first := service.New("first", "url_1", service.IndependentType, manager)
+	second := service.New("second", "url_2", service.IndependentType, nil)
+	second.Handlers = append(second.Handlers, &handlerConfig.Handler{Id: "main", Port: manager.Port + 1})
+
+	validChain, err := service.NewProxyChain([]string{"url_2"}, proxy, service.NewServiceDestination("url_1"))
+	s().NoError(err)
+
+	valid := New()
+	valid.Services = append(valid.Services, first, second)
+	valid.ProxyChains = append(valid.ProxyChains, validChain)
+	s().NoError(valid.Validate())
+
+	// every problem is returned at once
+	invalid := New()
+	duplicate := service.New("first", "url_3", service.IndependentType, nil)
+	duplicate.Handlers = append(duplicate.Handlers,
+		&handlerConfig.Handler{Id: "second_main", Port: manager.Port},
+		&handlerConfig.Handler{Id: "main"},
+	)
+	duplicate.Extensions = append(duplicate.Extensions, &clientConfig.Client{Id: "ext", ServiceUrl: "url_unknown"})
+	duplicate.Sources = append(duplicate.Sources, &service.Source{
+		Proxies: []*service.SourceService{{Proxy: proxy}},
+		Rule:    service.NewServiceDestination(),
+	})
+	unknownChain, err := service.NewProxyChain([]string{"url_unknown"}, proxy, service.NewServiceDestination("url_unknown"))
+	s().NoError(err)
+	invalidChain := &service.ProxyChain{Proxies: []*service.Proxy{proxy}, Destination: service.NewServiceDestination()}
+
+	invalid.Services = append(invalid.Services, first, second, duplicate)
+	invalid.ProxyChains = append(invalid.ProxyChains, unknownChain, invalidChain)
+
+	err = invalid.Validate()
+	s().Error(err)
+	validationErr, ok := err.(*engine.ValidationError)
+	s().True(ok)
+
+	keys := make([]string, len(validationErr.Problems))
+	for i, problem := range validationErr.Problems {
+		keys[i] = problem.Key
+	}
+	s().Equal([]string{
+		"services[2].id",
+		"services[2].handlers[0].port",
+		"services[2].handlers[1].id",
+		"services[2].extensions[0].url",
+		"services[2].sources[0].rule",
+		"services[2].sources[0].proxies[0].url",
+		"proxy_chains[0].sources[0]",
+		"proxy_chains[0].destination.urls[0]",
+		"proxy_chains[1].destination",
+	}, keys)
+
+	// the handler ids are registered, not the service id again
+	duplicateHandler := New()
+	s().NoError(duplicateHandler.SetService(service.New("third", "url_3", service.IndependentType, nil)))
+	duplicateHandler.Services = append(duplicateHandler.Services, second)
+	duplicateHandler.Services[0].Handlers = append(duplicateHandler.Services[0].Handlers, &handlerConfig.Handler{Id: "main"})
+	s().Error(duplicateHandler.RegisterId())
+	s().Error(duplicateHandler.Validate())
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestApp(t *testing.T) {
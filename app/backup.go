@@ -0,0 +1,121 @@
+package app
+
+import (
+	"fmt"
+	"github.com/ahmetson/os-lib/path"
+	"os"
+)
+
+//
+// Backups of the app configuration.
+//
+// Write keeps the previous versions of the file next to it: app.yml.1.bak is the latest version,
+// app.yml.2.bak is the version before it and so on.
+//
+
+// MaxBackups is the number of the previous versions kept by Write.
+// Zero disables the backups.
+var MaxBackups = 3
+
+// BackupPath returns the path of the n-th backup of the file.
+// The first backup is the latest.
+func BackupPath(filePath string, n int) string {
+	return fmt.Sprintf("%s.%d.bak", filePath, n)
+}
+
+// Backups returns the paths of the existing backups of the file.
+// The latest backup is the first.
+func Backups(filePath string) ([]string, error) {
+	backups := make([]string, 0, MaxBackups)
+	for n := 1; n <= MaxBackups; n++ {
+		backupPath := BackupPath(filePath, n)
+		exist, err := path.FileExist(backupPath)
+		if err != nil {
+			return nil, fmt.Errorf("path.FileExist('%s'): %w", backupPath, err)
+		}
+		if !exist {
+			break
+		}
+		backups = append(backups, backupPath)
+	}
+
+	return backups, nil
+}
+
+// Restore replaces the file with its n-th backup.
+// The first backup is the latest.
+//
+// The replaced version of the file becomes the latest backup,
+// so the restoring could be undone by restoring the first backup.
+func Restore(filePath string, n int) error {
+	if n < 1 || n > MaxBackups {
+		return fmt.Errorf("backup %d is out of range [1, %d]", n, MaxBackups)
+	}
+
+	return withLock(filePath, true, func() error {
+		return restore(filePath, n)
+	})
+}
+
+// ReadBackup reads the n-th backup of the file into the data, as Read does with the file.
+// Use it to check the backup before Restore.
+func ReadBackup(filePath string, n int, data interface{}) error {
+	if n < 1 || n > MaxBackups {
+		return fmt.Errorf("backup %d is out of range [1, %d]", n, MaxBackups)
+	}
+
+	return withLock(filePath, false, func() error {
+		backupPath := BackupPath(filePath, n)
+		buf, err := readFile(backupPath)
+		if err != nil {
+			return fmt.Errorf("readFile: %w", err)
+		}
+		// the backup keeps the format of the file
+		return decode(filePath, buf, data)
+	})
+}
+
+// restore the file from the backup without locking
+func restore(filePath string, n int) error {
+	backupPath := BackupPath(filePath, n)
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile('%s'): %w", backupPath, err)
+	}
+
+	if err := writeFile(filePath, data); err != nil {
+		return fmt.Errorf("writeFile('%s'): %w", filePath, err)
+	}
+
+	return nil
+}
+
+// rotateBackups shifts the backups of the file by one, the oldest backup is removed.
+// Then the current file is copied into the latest backup.
+// If the file doesn't exist, then nothing is done.
+func rotateBackups(filePath string) error {
+	if MaxBackups <= 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	for n := MaxBackups - 1; n >= 1; n-- {
+		err := os.Rename(BackupPath(filePath, n), BackupPath(filePath, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("os.Rename(%d): %w", n, err)
+		}
+	}
+
+	if err := os.WriteFile(BackupPath(filePath, 1), data, 0600); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+//
+// Formats of the app configuration file.
+//
+// The format is derived from the file extension: app.yml, app.yaml, app.json or app.toml.
+// The yaml format uses the yaml tags, the json and toml formats use the json tags.
+//
+
+const (
+	YamlType = "yaml"
+	JsonType = "json"
+	TomlType = "toml"
+)
+
+// DefaultExtension of the app configuration file, if the file name has no extension
+const DefaultExtension = ".yml"
+
+// Extensions of the app configuration files.
+// If the file name is given without the extension, then the files are searched in this order.
+var Extensions = []string{".yml", ".yaml", ".json", ".toml"}
+
+// FileType returns the format of the app configuration file by its extension.
+// If the extension is not supported, then returns an error.
+func FileType(filePath string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	switch ext {
+	case ".yml", ".yaml":
+		return YamlType, nil
+	case ".json":
+		return JsonType, nil
+	case ".toml":
+		return TomlType, nil
+	}
+
+	return "", fmt.Errorf("'%s' extension is not supported, use one of %v", ext, Extensions)
+}
+
+// splitExtension returns the file name without the supported extension and the extension.
+// If the file name has no supported extension, then the extension is empty.
+func splitExtension(fileName string) (string, string) {
+	ext := filepath.Ext(fileName)
+	if !slices.Contains(Extensions, strings.ToLower(ext)) {
+		return fileName, ""
+	}
+
+	return strings.TrimSuffix(fileName, ext), ext
+}
+
+// marshal the data in the format of the file
+func marshal(filePath string, data interface{}) ([]byte, error) {
+	fileType, err := FileType(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("FileType: %w", err)
+	}
+
+	switch fileType {
+	case JsonType:
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("json.MarshalIndent: %w", err)
+		}
+		return append(encoded, '\n'), nil
+	case TomlType:
+		// the toml is encoded by the json tags
+		raw, err := toRaw(data)
+		if err != nil {
+			return nil, fmt.Errorf("toRaw: %w", err)
+		}
+		encoded, err := toml.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("toml.Marshal: %w", err)
+		}
+		return encoded, nil
+	}
+
+	encoded, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal: %w", err)
+	}
+	return encoded, nil
+}
+
+// unmarshal the data in the format of the file
+func unmarshal(filePath string, buf []byte, data interface{}) error {
+	fileType, err := FileType(filePath)
+	if err != nil {
+		return fmt.Errorf("FileType: %w", err)
+	}
+
+	switch fileType {
+	case JsonType:
+		// the empty file is the empty app configuration, as in yaml
+		if len(bytes.TrimSpace(buf)) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(buf, data); err != nil {
+			return fmt.Errorf("json.Unmarshal: %w", err)
+		}
+	case TomlType:
+		raw := make(map[string]interface{})
+		if err := toml.Unmarshal(buf, &raw); err != nil {
+			return fmt.Errorf("toml.Unmarshal: %w", err)
+		}
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+		if err := json.Unmarshal(encoded, data); err != nil {
+			return fmt.Errorf("json.Unmarshal: %w", err)
+		}
+	default:
+		if err := yaml.Unmarshal(buf, data); err != nil {
+			return fmt.Errorf("yaml.Unmarshal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// toRaw converts the data into the generic maps by the json tags.
+// The integers are kept as int64, other numbers are float64.
+func toRaw(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+
+	return rawNumbers(raw), nil
+}
+
+// rawNumbers replaces the json.Number in the generic value by int64 or float64
+func rawNumbers(raw interface{}) interface{} {
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		for key, element := range value {
+			value[key] = rawNumbers(element)
+		}
+	case []interface{}:
+		for i, element := range value {
+			value[i] = rawNumbers(element)
+		}
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer
+		}
+		float, _ := value.Float64()
+		return float
+	}
+
+	return raw
+}
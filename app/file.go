@@ -7,9 +7,10 @@ import (
 	"github.com/ahmetson/datatype-lib/data_type/key_value"
 	"github.com/ahmetson/os-lib/arg"
 	"github.com/ahmetson/os-lib/path"
-	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 )
 
 //
@@ -19,7 +20,7 @@ import (
 // The ReadFileParameters returns the file path.
 // First it reads from a flag, then from environment variable.
 // Lastly, read the default file.
-func ReadFileParameters(configEngine *engine.Dev) (string, bool, error) {
+func ReadFileParameters(configEngine engine.Interface) (string, bool, error) {
 	// default app is empty
 	execPath, err := path.CurrentDir()
 	if err != nil {
@@ -68,16 +69,57 @@ func ReadFileParameters(configEngine *engine.Dev) (string, bool, error) {
 	return fileParamsToPath(fileParams), false, nil
 }
 
-// Read yaml file
+// Read the app configuration file: yaml, json or toml by the file extension.
+// The file is read under the shared lock, so it's not changed by other processes during the reading.
 func Read(filePath string, data interface{}) error {
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("os.Stat('%s'): %w", filePath, err)
+	}
+
+	return withLock(filePath, false, func() error {
+		return read(filePath, data)
+	})
+}
+
+// read the app configuration file without locking.
+// The app configuration of the older format is upgraded to the CurrentVersion, see Migrate.
+func read(filePath string, data interface{}) error {
+	buf, err := readFile(filePath)
+	if err != nil {
+		return fmt.Errorf("readFile: %w", err)
+	}
+
+	return decode(filePath, buf, data)
+}
+
+// decode the content of the app configuration file in the format of the filePath
+func decode(filePath string, buf []byte, data interface{}) error {
+	var err error
+	if _, ok := data.(*App); ok {
+		buf, _, err = migrateData(filePath, buf)
+		if err != nil {
+			return fmt.Errorf("migrateData('%s'): %w", filePath, err)
+		}
+	}
+
+	err = unmarshal(filePath, buf, data)
+	if err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return nil
+}
+
+// readFile returns the content of the file without locking
+func readFile(filePath string) ([]byte, error) {
 	info, err := os.Stat(filePath)
 	if err != nil {
-		return fmt.Errorf("os.Stat('%s'): %w", filePath, err)
+		return nil, fmt.Errorf("os.Stat('%s'): %w", filePath, err)
 	}
 
 	f, err := os.OpenFile(filePath, os.O_RDONLY, 0600)
 	if err != nil {
-		return fmt.Errorf("os.OpenFile('%s'): %w", filePath, err)
+		return nil, fmt.Errorf("os.OpenFile('%s'): %w", filePath, err)
 	}
 
 	buf := make([]byte, info.Size())
@@ -87,24 +129,20 @@ func Read(filePath string, data interface{}) error {
 	closeErr := f.Close()
 	if closeErr != nil {
 		if err != nil {
-			return fmt.Errorf("%v: file.Close: %w", err, closeErr)
+			return nil, fmt.Errorf("%v: file.Close: %w", err, closeErr)
 		} else {
-			return fmt.Errorf("file.Close: %w", closeErr)
+			return nil, fmt.Errorf("file.Close: %w", closeErr)
 		}
 	} else if err != nil {
-		return fmt.Errorf("file.Write: %w", err)
+		return nil, fmt.Errorf("file.Write: %w", err)
 	}
 
-	err = yaml.Unmarshal(buf, data)
-	if err != nil {
-		return fmt.Errorf("yaml.Unmarshal: %w", err)
-	}
-
-	return nil
+	return buf, nil
 }
 
 // flagExist checks is there any configuration flag.
 // If the configuration flag is set, it checks does it exist in the file system.
+// The file must have one of the Extensions.
 func flagExist(execPath string) (key_value.KeyValue, bool, error) {
 	if !arg.FlagExist(service.ConfigFlag) {
 		return nil, false, nil
@@ -123,33 +161,101 @@ func flagExist(execPath string) (key_value.KeyValue, bool, error) {
 		return nil, false, fmt.Errorf("file (%s) not found", absPath)
 	}
 
+	if _, err := FileType(absPath); err != nil {
+		return nil, false, fmt.Errorf("FileType('%s'): %w", absPath, err)
+	}
+
 	dir, fileName := path.DirAndFileName(absPath)
-	return engine.YamlPathParam(dir, fileName), true, nil
+	return filePathParam(dir, fileName, filepath.Ext(absPath)), true, nil
 }
 
 // envExist checks is there any configuration file path from env.
 // If it exists, checks does it exist in the file system.
 //
+// If the engine has a profile, then the profile file is checked first: app.<profile>.yml.
+// If the profile file doesn't exist, then falls back to app.yml.
+//
+// The configuration name could have one of the Extensions, for example app.json.
+// Without the extension, the first existing file is used in the order of Extensions.
+//
 // In case if it doesn't exist, it will try to load the default configuration.
-func envExist(configEngine *engine.Dev) (key_value.KeyValue, bool, error) {
+func envExist(configEngine engine.Interface) (key_value.KeyValue, bool, error) {
 	if !configEngine.Exist(EnvConfigName) || !configEngine.Exist(EnvConfigPath) {
 		return nil, false, nil
 	}
 
-	configName := configEngine.GetString(EnvConfigName)
-	configPath := configEngine.GetString(EnvConfigPath)
-	absPath := path.AbsDir(configPath, configName+".yml")
-	exists, err := path.FileExist(absPath)
+	configName, err := engine.ToString(configEngine.Get(EnvConfigName))
 	if err != nil {
-		return nil, false, fmt.Errorf("path.FileExists('%s'): %w", absPath, err)
+		return nil, false, fmt.Errorf("engine.ToString('%s'): %w", EnvConfigName, err)
+	}
+	configPath, err := engine.ToString(configEngine.Get(EnvConfigPath))
+	if err != nil {
+		return nil, false, fmt.Errorf("engine.ToString('%s'): %w", EnvConfigPath, err)
+	}
+
+	configName, ext := splitExtension(configName)
+
+	// the profile file is preferred, for example app.dev.yml
+	profile := ""
+	if profiled, ok := configEngine.(interface{ Profile() string }); ok {
+		profile = profiled.Profile()
+	}
+	if len(profile) > 0 {
+		profileName := configName + "." + profile
+		profileExt, exists, err := findFile(configPath, profileName, ext)
+		if err != nil {
+			return nil, false, fmt.Errorf("findFile('%s'): %w", profileName, err)
+		}
+		if exists {
+			return filePathParam(configPath, profileName, profileExt), true, nil
+		}
 	}
 
-	envPath := engine.YamlPathParam(configPath, configName)
+	fileExt, exists, err := findFile(configPath, configName, ext)
+	if err != nil {
+		return nil, false, fmt.Errorf("findFile('%s'): %w", configName, err)
+	}
+
+	envPath := filePathParam(configPath, configName, fileExt)
 	return envPath, exists, nil
 }
 
+// findFile returns the extension of the existing configuration file with the name.
+// If the extension is given, then only it is checked.
+// If the file doesn't exist, then returns the given extension or the DefaultExtension.
+func findFile(configPath string, configName string, ext string) (string, bool, error) {
+	extensions := Extensions
+	if len(ext) > 0 {
+		extensions = []string{ext}
+	}
+
+	for _, candidate := range extensions {
+		absPath := path.AbsDir(configPath, configName+candidate)
+		exists, err := path.FileExist(absPath)
+		if err != nil {
+			return "", false, fmt.Errorf("path.FileExists('%s'): %w", absPath, err)
+		}
+		if exists {
+			return candidate, true, nil
+		}
+	}
+
+	if len(ext) > 0 {
+		return ext, false, nil
+	}
+	return DefaultExtension, false, nil
+}
+
+// filePathParam creates a file parameter with the file extension
+func filePathParam(configPath string, configName string, ext string) key_value.KeyValue {
+	fileType, _ := FileType(ext)
+	return engine.YamlPathParam(configPath, configName).
+		Set("type", fileType).
+		Set("extension", ext)
+}
+
 // setDefault paths of the local file to load by default
-func setDefault(execPath string, engine *engine.Dev) {
+func setDefault(execPath string, engine engine.Interface) {
 	engine.SetDefault(EnvConfigName, "app")
 	engine.SetDefault(EnvConfigPath, execPath)
 }
@@ -176,36 +282,128 @@ func MakeConfigDir(filePath string) error {
 	return nil
 }
 
+// OverridesPath returns the path of the file where the parameters set at runtime are persisted.
+// The file is stored next to the app configuration: app.yml overrides are in app.overrides.yml.
+func OverridesPath(filePath string) string {
+	dir, fileName := path.DirAndFileName(filePath)
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	return filepath.Join(dir, name+".overrides.yml")
+}
+
+// fileParamsToPath returns the path of the file parameter.
+// The file parameter without the extension is the yaml file.
 func fileParamsToPath(fileParams key_value.KeyValue) string {
 	name, _ := fileParams.StringValue("name")
 	dirPath, _ := fileParams.StringValue("configPath")
-	return filepath.Join(dirPath, name+".yml")
+	ext, _ := fileParams.StringValue("extension")
+	if len(ext) == 0 {
+		ext = DefaultExtension
+	}
+	return filepath.Join(dirPath, name+ext)
 }
 
-// Write the service as the yaml on the given path.
-// If the path doesn't contain the file extension, it will through an error
+// Write the service on the given path.
+// The format is derived from the file extension: yaml, json or toml.
+// If the path doesn't contain the supported file extension, it will through an error
+//
+// The file is replaced atomically, so a crash in the middle of the writing doesn't corrupt the file.
+// The previous version of the file is kept as the backup, see Backups.
+//
+// The file is written under the exclusive lock.
+// To keep the changes of other processes, use Update.
 func Write(filePath string, data interface{}) error {
-	appConfig, err := yaml.Marshal(data)
+	return withLock(filePath, true, func() error {
+		return write(filePath, data)
+	})
+}
+
+// write the service without locking
+func write(filePath string, data interface{}) error {
+	appConfig, err := marshal(filePath, data)
 	if err != nil {
-		return fmt.Errorf("yaml.Marshal: %w", err)
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	if err := writeFile(filePath, appConfig); err != nil {
+		return fmt.Errorf("writeFile('%s'): %w", filePath, err)
+	}
+
+	return nil
+}
+
+// writeFile replaces the file with the data atomically.
+// The data is written into the temporary file in the same directory,
+// then the temporary file is renamed to the file path.
+//
+// The previous version of the file is rotated into the backups.
+func writeFile(filePath string, data []byte) error {
+	dir, fileName := filepath.Split(filePath)
+	if len(dir) == 0 {
+		dir = "."
 	}
 
-	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, 0600)
+	f, err := os.CreateTemp(dir, "."+fileName+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("os.OpenFile('%s'): %w", filePath, err)
+		return fmt.Errorf("os.CreateTemp: %w", err)
 	}
+	tempPath := f.Name()
 
-	_, err = f.Write(appConfig)
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
 	closeErr := f.Close()
 	if closeErr != nil {
+		_ = os.Remove(tempPath)
 		if err != nil {
 			return fmt.Errorf("%v: file.Close: %w", err, closeErr)
 		} else {
 			return fmt.Errorf("file.Close: %w", closeErr)
 		}
 	} else if err != nil {
+		_ = os.Remove(tempPath)
 		return fmt.Errorf("file.Write: %w", err)
 	}
 
+	if err := rotateBackups(filePath); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("rotateBackups: %w", err)
+	}
+
+	if err := os.Rename(tempPath, filePath); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	// the rename is durable after the directory is synced.
+	// windows doesn't support syncing the directories.
+	if runtime.GOOS != "windows" {
+		if err := syncDir(dir); err != nil {
+			return fmt.Errorf("syncDir('%s'): %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// syncDir flushes the directory entries to the disk
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+
+	err = f.Sync()
+	closeErr := f.Close()
+	if closeErr != nil {
+		if err != nil {
+			return fmt.Errorf("%v: file.Close: %w", err, closeErr)
+		} else {
+			return fmt.Errorf("file.Close: %w", closeErr)
+		}
+	} else if err != nil {
+		return fmt.Errorf("file.Sync: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+//
+// Advisory locking of the app configuration.
+//
+// Multiple processes could read and write the same app configuration.
+// The readers share the lock, while the writer holds it exclusively.
+//
+// The lock is held on the separate app.yml.lock file,
+// since the app configuration itself is replaced by Write.
+// The lock file is created by the first writer and kept next to the app configuration,
+// removing it would let two writers lock the different files.
+//
+// The readers never create the lock file. If it doesn't exist or can't be opened,
+// for example in the read-only directory, then the file is read without the lock.
+// Write replaces the file atomically, so such reader still gets the whole file.
+//
+
+// LockPath returns the path of the lock file of the app configuration
+func LockPath(filePath string) string {
+	return filePath + ".lock"
+}
+
+// withLock calls the fn while holding the lock of the file.
+func withLock(filePath string, exclusive bool, fn func() error) error {
+	fileLock, err := lock(filePath, exclusive)
+	if err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+
+	err = fn()
+	if unlockErr := fileLock.unlock(); unlockErr != nil {
+		if err != nil {
+			return fmt.Errorf("%v: fileLock.unlock: %w", err, unlockErr)
+		}
+		return fmt.Errorf("fileLock.unlock: %w", unlockErr)
+	}
+
+	return err
+}
+
+// fileLock is the advisory lock held by the process
+type fileLock struct {
+	f *os.File
+}
+
+// lock blocks until the lock of the file is acquired.
+// The exclusive lock creates the lock file if it doesn't exist.
+// The shared lock returns nil if the lock file doesn't exist or is not accessible.
+func lock(filePath string, exclusive bool) (*fileLock, error) {
+	lockPath := LockPath(filePath)
+
+	var f *os.File
+	var err error
+	if exclusive {
+		f, err = os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	} else {
+		f, err = os.Open(lockPath)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("os.OpenFile('%s'): %w", lockPath, err)
+	}
+
+	if err := lockFile(f, exclusive); err != nil {
+		closeErr := f.Close()
+		if closeErr != nil {
+			return nil, fmt.Errorf("%v: file.Close: %w", err, closeErr)
+		}
+		return nil, fmt.Errorf("lockFile('%s'): %w", lockPath, err)
+	}
+
+	return &fileLock{f: f}, nil
+}
+
+// unlock releases the lock, the nil lock is skipped
+func (l *fileLock) unlock() error {
+	if l == nil {
+		return nil
+	}
+
+	err := unlockFile(l.f)
+	closeErr := l.f.Close()
+	if closeErr != nil {
+		if err != nil {
+			return fmt.Errorf("%v: file.Close: %w", err, closeErr)
+		} else {
+			return fmt.Errorf("file.Close: %w", closeErr)
+		}
+	} else if err != nil {
+		return fmt.Errorf("unlockFile: %w", err)
+	}
+
+	return nil
+}
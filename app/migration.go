@@ -0,0 +1,332 @@
+package app
+
+import (
+	"fmt"
+	"github.com/ahmetson/config-lib/engine"
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+//
+// Versioning of the app configuration format.
+//
+// The app configuration keeps the version of its format in the version key.
+// The files without the version key are version 0.
+//
+// When the fields of the service.Service or service.ProxyChain change,
+// the CurrentVersion is increased, and the migration from the previous version is registered.
+// Read upgrades the older files step by step: 0 to 1, 1 to 2 and so on.
+// The upgraded file is written by the next Write, or right away by MigrateFile.
+//
+
+// CurrentVersion is the version of the app configuration format written by this package
+const CurrentVersion = 1
+
+// VersionKey is the key of the format version in the app configuration
+const VersionKey = "version"
+
+// Migration upgrades the app configuration from the From version to the next version.
+// The Migrate changes the raw document in place, the version key is set by the caller.
+//
+// The nested sections of the document are map[string]interface{} and the lists are []interface{},
+// whatever the format of the file is.
+type Migration struct {
+	From        int
+	Description string
+	Migrate     func(doc key_value.KeyValue) error
+}
+
+// MigrationReport describes the upgrade of the app configuration
+type MigrationReport struct {
+	From    int      `json:"from" yaml:"from"`
+	To      int      `json:"to" yaml:"to"`
+	Steps   []string `json:"steps" yaml:"steps"`     // descriptions of the applied migrations
+	Changes []string `json:"changes" yaml:"changes"` // changed keys, such as "+ version: 1"
+}
+
+// Changed returns true if the migration changed the app configuration
+func (report *MigrationReport) Changed() bool {
+	return report.From != report.To || len(report.Changes) > 0
+}
+
+// migrations by the version they upgrade from
+var migrations = struct {
+	sync.RWMutex
+	byVersion map[int]*Migration
+}{byVersion: map[int]*Migration{
+	0: {
+		From:        0,
+		Description: "add the version of the format",
+		Migrate:     func(key_value.KeyValue) error { return nil },
+	},
+}}
+
+// RegisterMigration adds the migration to the registry.
+// Returns an error if the migration from the same version is registered already.
+func RegisterMigration(migration *Migration) error {
+	if migration == nil || migration.Migrate == nil {
+		return fmt.Errorf("migration or its Migrate function is nil")
+	}
+	if migration.From < 0 {
+		return fmt.Errorf("migration from the negative version %d", migration.From)
+	}
+
+	migrations.Lock()
+	defer migrations.Unlock()
+	if _, ok := migrations.byVersion[migration.From]; ok {
+		return fmt.Errorf("migration from the version %d is registered already", migration.From)
+	}
+
+	migrations.byVersion[migration.From] = migration
+	return nil
+}
+
+// unregisterMigration removes the migration from the registry
+func unregisterMigration(from int) {
+	migrations.Lock()
+	delete(migrations.byVersion, from)
+	migrations.Unlock()
+}
+
+// migrationFrom returns the registered migration from the version
+func migrationFrom(version int) (*Migration, bool) {
+	migrations.RLock()
+	defer migrations.RUnlock()
+	migration, ok := migrations.byVersion[version]
+	return migration, ok
+}
+
+// DocVersion returns the format version of the raw app configuration.
+// The document without the version key is version 0.
+func DocVersion(doc key_value.KeyValue) (int, error) {
+	raw, ok := doc[VersionKey]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	version, err := engine.ToInt64(raw)
+	if err != nil {
+		return 0, fmt.Errorf("engine.ToInt64('%v'): %w", raw, err)
+	}
+	if version < 0 {
+		return 0, fmt.Errorf("negative version %d", version)
+	}
+
+	return int(version), nil
+}
+
+// Migrate upgrades the raw app configuration to the target version in place.
+// Each migration is applied in order, after each one the version key is set to the next version.
+//
+// Returns an error if the document is newer than the target, or if any migration is not registered.
+// On error, the document could be upgraded partially.
+func Migrate(doc key_value.KeyValue, target int) (*MigrationReport, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("doc is nil")
+	}
+	from, err := DocVersion(doc)
+	if err != nil {
+		return nil, fmt.Errorf("DocVersion: %w", err)
+	}
+	if from > target {
+		return nil, fmt.Errorf("version %d is newer than the supported version %d", from, target)
+	}
+
+	before, err := copyDoc(doc)
+	if err != nil {
+		return nil, fmt.Errorf("copyDoc: %w", err)
+	}
+
+	// the decoders keep the type of the document for the nested maps
+	for key, value := range doc {
+		doc[key] = plainValue(value)
+	}
+
+	report := &MigrationReport{From: from, To: target, Steps: make([]string, 0, target-from)}
+	for version := from; version < target; version++ {
+		migration, ok := migrationFrom(version)
+		if !ok {
+			return nil, fmt.Errorf("no migration from the version %d", version)
+		}
+		if err := migration.Migrate(doc); err != nil {
+			return nil, fmt.Errorf("migration from the version %d: %w", version, err)
+		}
+		doc[VersionKey] = version + 1
+
+		report.Steps = append(report.Steps, fmt.Sprintf("%d -> %d: %s", version, version+1, migration.Description))
+	}
+
+	after, err := copyDoc(doc)
+	if err != nil {
+		return nil, fmt.Errorf("copyDoc: %w", err)
+	}
+	report.Changes = docChanges("", before, after)
+
+	return report, nil
+}
+
+// MigrateFile upgrades the app configuration in the file to the CurrentVersion.
+// In the dry run, the file is not changed, the report shows what the migration would change.
+//
+// The former version of the file is kept as the backup, see Backups.
+func MigrateFile(filePath string, dryRun bool) (*MigrationReport, error) {
+	var report *MigrationReport
+	err := withLock(filePath, !dryRun, func() error {
+		data, err := readFile(filePath)
+		if err != nil {
+			return fmt.Errorf("readFile: %w", err)
+		}
+
+		var migrated []byte
+		migrated, report, err = migrateData(filePath, data)
+		if err != nil {
+			return fmt.Errorf("migrateData: %w", err)
+		}
+		if dryRun || !report.Changed() {
+			return nil
+		}
+
+		var upgraded App
+		if err := unmarshal(filePath, migrated, &upgraded); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+		upgraded.SetEmptyFields()
+		if err := write(filePath, &upgraded); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// migrateData upgrades the content of the app configuration file to the CurrentVersion.
+// If the data is at the CurrentVersion, then it's returned as is.
+func migrateData(filePath string, data []byte) ([]byte, *MigrationReport, error) {
+	doc := key_value.New()
+	if err := unmarshal(filePath, data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	// the empty file
+	if doc == nil {
+		doc = key_value.New()
+	}
+
+	report, err := Migrate(doc, CurrentVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Migrate: %w", err)
+	}
+	if !report.Changed() {
+		return data, report, nil
+	}
+
+	migrated, err := marshal(filePath, map[string]interface{}(doc))
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return migrated, report, nil
+}
+
+// plainValue converts the nested maps of the raw value into map[string]interface{}
+func plainValue(raw interface{}) interface{} {
+	switch value := raw.(type) {
+	case key_value.KeyValue:
+		return plainValue(map[string]interface{}(value))
+	case map[string]interface{}:
+		for key, element := range value {
+			value[key] = plainValue(element)
+		}
+		return value
+	case []interface{}:
+		for i, element := range value {
+			value[i] = plainValue(element)
+		}
+		return value
+	}
+
+	return raw
+}
+
+// copyDoc returns the deep copy of the raw document
+func copyDoc(doc key_value.KeyValue) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(map[string]interface{}(doc))
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal: %w", err)
+	}
+	copied := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	return copied, nil
+}
+
+// docChanges lists the differences between the raw documents:
+// "+ key: value" is added, "- key: value" is removed and "~ key: old -> new" is changed.
+// The nested keys are joined by the dots, the list elements are indexed: services[0].id.
+func docChanges(prefix string, before interface{}, after interface{}) []string {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for key := range beforeMap {
+			keys = append(keys, key)
+		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		changes := make([]string, 0)
+		for _, key := range keys {
+			path := key
+			if len(prefix) > 0 {
+				path = prefix + "." + key
+			}
+			beforeValue, inBefore := beforeMap[key]
+			afterValue, inAfter := afterMap[key]
+			switch {
+			case !inBefore:
+				changes = append(changes, fmt.Sprintf("+ %s: %v", path, afterValue))
+			case !inAfter:
+				changes = append(changes, fmt.Sprintf("- %s: %v", path, beforeValue))
+			default:
+				changes = append(changes, docChanges(path, beforeValue, afterValue)...)
+			}
+		}
+		return changes
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		changes := make([]string, 0)
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			path := prefix + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(beforeList):
+				changes = append(changes, fmt.Sprintf("+ %s: %v", path, afterList[i]))
+			case i >= len(afterList):
+				changes = append(changes, fmt.Sprintf("- %s: %v", path, beforeList[i]))
+			default:
+				changes = append(changes, docChanges(path, beforeList[i], afterList[i])...)
+			}
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return []string{}
+	}
+	return []string{fmt.Sprintf("~ %s: %v -> %v", prefix, before, after)}
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ahmetson/config-lib/service"
+	"github.com/ahmetson/os-lib/path"
+	"gopkg.in/yaml.v3"
+	"slices"
+	"strings"
+)
+
+// ConflictError is returned by Update if the services or proxy chains changed by the update
+// were changed by another process too.
+//
+// The proxy chains have no ids, so they are compared as a whole.
+type ConflictError struct {
+	Services    []string // ids of the conflicting services
+	ProxyChains bool     // true if the proxy chains are conflicting
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.Services) == 0 {
+		return "proxy chains changed by another process"
+	}
+	if e.ProxyChains {
+		return fmt.Sprintf("services changed by another process: %s; and proxy chains", strings.Join(e.Services, ", "))
+	}
+	return fmt.Sprintf("services changed by another process: %s", strings.Join(e.Services, ", "))
+}
+
+// Update the app configuration by re-reading, merging and writing the file under the exclusive lock.
+//
+// The update is applied to the latest version of the file rather than to the base,
+// so the changes of the other processes are kept.
+// The base is the app configuration as the caller read or wrote it last time.
+//
+// If the service or the proxy chains changed by the update were changed in the file since the base,
+// then nothing is written and *ConflictError is returned along with the latest version of the file.
+//
+// Returns the updated app configuration.
+func Update(filePath string, base *App, update func(current *App) error) (*App, error) {
+	var current *App
+	err := withLock(filePath, true, func() error {
+		var err error
+		current, err = merge(filePath, base, update)
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*ConflictError); ok {
+			return current, err
+		}
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// merge applies the update to the latest version of the file and writes it.
+// The caller must hold the lock.
+func merge(filePath string, base *App, update func(current *App) error) (*App, error) {
+	current, exist, err := readLatest(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("readLatest: %w", err)
+	}
+
+	before, err := serviceVersions(current)
+	if err != nil {
+		return nil, fmt.Errorf("serviceVersions: %w", err)
+	}
+	beforeChains, err := yaml.Marshal(current.ProxyChains)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal('proxy_chains'): %w", err)
+	}
+	beforeApp, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal: %w", err)
+	}
+
+	if err := update(current); err != nil {
+		return nil, fmt.Errorf("update: %w", err)
+	}
+
+	after, err := serviceVersions(current)
+	if err != nil {
+		return nil, fmt.Errorf("serviceVersions: %w", err)
+	}
+	afterChains, err := yaml.Marshal(current.ProxyChains)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal('proxy_chains'): %w", err)
+	}
+	baseVersions := make(map[string][]byte)
+	var baseChains []*service.ProxyChain
+	if base != nil {
+		baseVersions, err = serviceVersions(base)
+		if err != nil {
+			return nil, fmt.Errorf("serviceVersions: %w", err)
+		}
+		baseChains = base.ProxyChains
+	}
+	baseChainsVersion, err := yaml.Marshal(baseChains)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal('proxy_chains'): %w", err)
+	}
+
+	conflicts := make([]string, 0)
+	for id, version := range after {
+		// not changed by the update
+		if bytes.Equal(version, before[id]) {
+			continue
+		}
+		// changed by another process since the base
+		if !bytes.Equal(before[id], baseVersions[id]) {
+			conflicts = append(conflicts, id)
+		}
+	}
+	// changed by the update and by another process since the base
+	chainsConflict := !bytes.Equal(afterChains, beforeChains) && !bytes.Equal(beforeChains, baseChainsVersion)
+	if len(conflicts) > 0 || chainsConflict {
+		slices.Sort(conflicts)
+		// the update changed the current app
+		latest, _, err := readLatest(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("readLatest: %w", err)
+		}
+		return latest, &ConflictError{Services: conflicts, ProxyChains: chainsConflict}
+	}
+
+	afterApp, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal: %w", err)
+	}
+	if exist && bytes.Equal(beforeApp, afterApp) {
+		return current, nil
+	}
+
+	if err := write(filePath, current); err != nil {
+		return nil, fmt.Errorf("write: %w", err)
+	}
+
+	return current, nil
+}
+
+// readLatest returns the app configuration from the file.
+// If the file doesn't exist, then returns an empty app configuration and false.
+// The caller must hold the lock.
+func readLatest(filePath string) (*App, bool, error) {
+	latest := New()
+	exist, err := path.FileExist(filePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("path.FileExist('%s'): %w", filePath, err)
+	}
+	if !exist {
+		return latest, false, nil
+	}
+
+	if err := read(filePath, latest); err != nil {
+		return nil, false, fmt.Errorf("read: %w", err)
+	}
+	latest.SetEmptyFields()
+	if err := latest.RegisterId(); err != nil {
+		return nil, false, fmt.Errorf("app.RegisterId: %w", err)
+	}
+
+	return latest, true, nil
+}
+
+// serviceVersions returns the yaml of each service by the service id
+func serviceVersions(a *App) (map[string][]byte, error) {
+	versions := make(map[string][]byte, len(a.Services))
+	for _, s := range a.Services {
+		version, err := yaml.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("yaml.Marshal('%s'): %w", s.Id, err)
+		}
+		versions[s.Id] = version
+	}
+
+	return versions, nil
+}
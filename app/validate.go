@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	clientConfig "github.com/ahmetson/client-lib/config"
+	"github.com/ahmetson/config-lib/engine"
+	"github.com/ahmetson/config-lib/service"
+)
+
+//
+// Validation of the whole app configuration.
+//
+// The services refer to each other by the urls: the extensions, the proxy sources
+// and the proxy chain destinations must point to the services in the app configuration.
+// The ids of the services, handlers and managers are unique within the app,
+// and no two handlers or managers listen to the same port.
+//
+
+// validator collects the problems of the app configuration
+type validator struct {
+	app      *App
+	problems []*engine.Problem
+	ids      map[string]string // the key of the first element by id
+	ports    map[uint64]string // the key of the first element by port
+}
+
+// Validate checks the app configuration as a whole.
+// Returns *engine.ValidationError that lists every problem, or nil if the configuration is valid.
+//
+// The problem keys are the paths in the configuration, for example services[0].handlers[1].id.
+func (a *App) Validate() error {
+	if a == nil {
+		return fmt.Errorf("app struct is nil")
+	}
+
+	v := &validator{
+		app:      a,
+		problems: make([]*engine.Problem, 0),
+		ids:      make(map[string]string),
+		ports:    make(map[uint64]string),
+	}
+
+	for i, s := range a.Services {
+		v.service(fmt.Sprintf("services[%d]", i), s)
+	}
+	for i, proxyChain := range a.ProxyChains {
+		v.proxyChain(fmt.Sprintf("proxy_chains[%d]", i), proxyChain)
+	}
+
+	if len(v.problems) > 0 {
+		return &engine.ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// problem adds the problem of the key
+func (v *validator) problem(key string, format string, args ...interface{}) {
+	v.problems = append(v.problems, &engine.Problem{Key: key, Reason: fmt.Sprintf(format, args...)})
+}
+
+// id registers the id, the empty and duplicate ids are the problems
+func (v *validator) id(key string, id string) {
+	if len(id) == 0 {
+		v.problem(key, "id is empty")
+		return
+	}
+	if first, ok := v.ids[id]; ok {
+		v.problem(key, "'%s' id is duplicate of %s", id, first)
+		return
+	}
+	v.ids[id] = key
+}
+
+// port registers the port, the port used by another handler or manager is the problem.
+// The zero port is not allocated yet, so it's skipped.
+func (v *validator) port(key string, port uint64) {
+	if port == 0 {
+		return
+	}
+	if first, ok := v.ports[port]; ok {
+		v.problem(key, "port %d is used by %s", port, first)
+		return
+	}
+	v.ports[port] = key
+}
+
+// serviceUrl checks that the url belongs to the service in the app configuration
+func (v *validator) serviceUrl(key string, url string, what string) {
+	if len(url) == 0 {
+		v.problem(key, "%s url is empty", what)
+		return
+	}
+	if v.app.ServiceByUrl(url) == nil {
+		v.problem(key, "%s points to unknown service '%s'", what, url)
+	}
+}
+
+// rule checks that the rule is valid, and its urls belong to the services
+func (v *validator) rule(key string, rule *service.Rule) {
+	if !rule.IsValid() {
+		v.problem(key, "rule is invalid, it must be a service, handler or route rule with not excluded commands")
+		return
+	}
+	for i, url := range rule.Urls {
+		v.serviceUrl(fmt.Sprintf("%s.urls[%d]", key, i), url, "destination")
+	}
+}
+
+func (v *validator) service(key string, s *service.Service) {
+	if s == nil {
+		v.problem(key, "service is nil")
+		return
+	}
+
+	v.id(key+".id", s.Id)
+	if len(s.Url) == 0 {
+		v.problem(key+".url", "url is empty")
+	}
+	if s.Manager != nil {
+		v.id(key+".manager.id", s.Manager.Id)
+		v.port(key+".manager.port", s.Manager.Port)
+	}
+
+	for i, h := range s.Handlers {
+		handlerKey := fmt.Sprintf("%s.handlers[%d]", key, i)
+		if h == nil {
+			v.problem(handlerKey, "handler is nil")
+			continue
+		}
+		v.id(handlerKey+".id", h.Id)
+		v.port(handlerKey+".port", h.Port)
+	}
+
+	for i, extension := range s.Extensions {
+		v.client(fmt.Sprintf("%s.extensions[%d]", key, i), extension, "extension")
+	}
+
+	for i, source := range s.Sources {
+		sourceKey := fmt.Sprintf("%s.sources[%d]", key, i)
+		if source == nil {
+			v.problem(sourceKey, "source is nil")
+			continue
+		}
+		if source.Rule != nil {
+			v.rule(sourceKey+".rule", source.Rule)
+		}
+		for j, proxy := range source.Proxies {
+			proxyKey := fmt.Sprintf("%s.proxies[%d]", sourceKey, j)
+			if proxy == nil || proxy.Proxy == nil {
+				v.problem(proxyKey, "proxy is nil")
+				continue
+			}
+			v.serviceUrl(proxyKey+".url", proxy.Url, "proxy source")
+		}
+	}
+}
+
+// client checks that the client connects to the service in the app configuration
+func (v *validator) client(key string, client *clientConfig.Client, what string) {
+	if client == nil {
+		v.problem(key, "%s is nil", what)
+		return
+	}
+	v.serviceUrl(key+".url", client.ServiceUrl, what)
+}
+
+func (v *validator) proxyChain(key string, proxyChain *service.ProxyChain) {
+	if proxyChain == nil {
+		v.problem(key, "proxy chain is nil")
+		return
+	}
+
+	if !proxyChain.IsProxiesValid() {
+		v.problem(key+".proxies", "proxies are empty, duplicate or missing the id, url or category")
+	}
+	if !service.IsStringSliceValid(proxyChain.Sources) && proxyChain.Sources != nil {
+		v.problem(key+".sources", "sources are empty or duplicate")
+	}
+	for i, url := range proxyChain.Sources {
+		v.serviceUrl(fmt.Sprintf("%s.sources[%d]", key, i), url, "proxy source")
+	}
+
+	if proxyChain.Destination == nil {
+		v.problem(key+".destination", "destination is missing")
+		return
+	}
+	v.rule(key+".destination", proxyChain.Destination)
+}
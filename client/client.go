@@ -4,34 +4,60 @@ import (
 	"fmt"
 	"github.com/ahmetson/client-lib"
 	clientConfig "github.com/ahmetson/client-lib/config"
+	"github.com/ahmetson/config-lib/engine"
 	"github.com/ahmetson/config-lib/handler"
 	"github.com/ahmetson/config-lib/service"
 	"github.com/ahmetson/datatype-lib/data_type/key_value"
 	"github.com/ahmetson/datatype-lib/message"
 	handlerConfig "github.com/ahmetson/handler-lib/config"
 	"github.com/ahmetson/handler-lib/manager_client"
+	"strconv"
 	"time"
 )
 
 type Client struct {
-	socket *client.Socket
+	socket    *client.Socket
+	strict    bool
+	serviceId string // scope of the parameter reads
+	handlerId string // scope of the parameter reads within the service
 }
 
 type Interface interface {
 	Close() error
 	Timeout(duration time.Duration)
 	Attempt(attempt uint8)
+	Strict(enabled bool)
+	WithStrict(enabled bool) Interface
+	Scope(serviceId string, handlerId string)
 
 	Service(id string) (*service.Service, error)
 	ServiceByUrl(url string) (*service.Service, error)
 	SetService(s *service.Service) error
+	RestoreApp(backup int) error
 	GenerateHandler(handlerType handlerConfig.HandlerType, category string, internal bool) (*handlerConfig.Handler, error)
 
 	Exist(name string) (bool, error)
 	String(name string) (string, error)
 	Uint64(name string) (uint64, error)
 	Bool(name string) (bool, error)
+	Float64(name string) (float64, error)
+	Int64(name string) (int64, error)
+	Duration(name string) (time.Duration, error)
+	StringSlice(name string) ([]string, error)
+	StringMap(name string) (map[string]string, error)
 	SetDefault(name string, value interface{}) error
+	SetDefaultAck(name string, value interface{}) error
+	SetDefaults(params key_value.KeyValue) ([]string, error)
+	Set(name string, value interface{}) error
+	Unset(name string) (bool, error)
+	RegisterSchema(schemas ...*engine.Schema) error
+	Validate() error
+	Source(name string) (string, *engine.Source, error)
+	Sources() (map[string]*engine.Source, error)
+	Export(format engine.ExportFormat, secretPatterns ...string) (string, error)
+	Unmarshal(target interface{}) error
+	Tree(prefix string) (key_value.KeyValue, error)
+	SetServiceParam(serviceId string, handlerId string, name string, value interface{}) error
 	ServiceExist(id string) (bool, error)
 	ServiceExistByUrl(url string) (bool, error)
 	GenerateService(id string, url string, serviceType service.Type) (*service.Service, error)
@@ -91,6 +117,59 @@ func (c *Client) Attempt(attempt uint8) {
 	c.socket.Attempt(attempt)
 }
 
+// Strict enables or disables the strict mode of the parameter reads.
+// In the strict mode, the missing parameter, a failed conversion or interpolation returns *ParamError.
+// Otherwise, the zero value is returned.
+//
+// By default, the strict mode is disabled.
+// Use WithStrict to change the mode of a single read.
+func (c *Client) Strict(enabled bool) {
+	if c == nil {
+		return
+	}
+	c.strict = enabled
+}
+
+// WithStrict returns the client that reads the parameters in the given mode.
+// The returned client shares the connection and scope with c, the mode of c is not changed:
+//
+//	port, err := c.WithStrict(true).Uint64("PORT")
+//
+// Close c, rather than the returned client.
+func (c *Client) WithStrict(enabled bool) Interface {
+	if c == nil {
+		return c
+	}
+	strictClient := *c
+	strictClient.strict = enabled
+	return &strictClient
+}
+
+// Scope the parameter reads by the service and optionally by its handler.
+// The parameters of the service, or the handler, are preferred over the global parameters.
+// If the service doesn't have the parameter, then the global parameter is returned.
+//
+// The empty serviceId reads the global parameters only.
+func (c *Client) Scope(serviceId string, handlerId string) {
+	if c == nil {
+		return
+	}
+	c.serviceId = serviceId
+	c.handlerId = handlerId
+}
+
+// scope adds the scope of the parameter reads into the request parameters
+func (c *Client) scope(params key_value.KeyValue) key_value.KeyValue {
+	if len(c.serviceId) == 0 {
+		return params
+	}
+	params.Set("service", c.serviceId)
+	if len(c.handlerId) > 0 {
+		params.Set("handler", c.handlerId)
+	}
+	return params
+}
+
 func (c *Client) Service(id string) (*service.Service, error) {
 	if c == nil || c.socket == nil {
 		return nil, fmt.Errorf("nil or closed")
@@ -181,6 +260,30 @@ func (c *Client) SetService(s *service.Service) error {
 	return nil
 }
 
+// RestoreApp replaces the app configuration with the backup written before the last changes.
+// The first backup is the latest. See app.Restore.
+func (c *Client) RestoreApp(backup int) error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.RestoreApp,
+		Parameters: key_value.New().Set("backup", uint64(backup)),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.RestoreApp, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	return nil
+}
+
 // GenerateHandler creates a configuration that could be added into the service
 func (c *Client) GenerateHandler(handlerType handlerConfig.HandlerType, category string, internal bool) (*handlerConfig.Handler, error) {
 	if c == nil || c.socket == nil {
@@ -263,7 +366,7 @@ func (c *Client) Exist(name string) (bool, error) {
 
 	req := message.Request{
 		Command:    handler.ParamExist,
-		Parameters: key_value.New().Set("name", name),
+		Parameters: c.scope(key_value.New().Set("name", name)),
 	}
 
 	rep, err := c.socket.Request(&req)
@@ -283,27 +386,40 @@ func (c *Client) Exist(name string) (bool, error) {
 	return exist, nil
 }
 
-// String parameter from config engine
-func (c *Client) String(name string) (string, error) {
+// param requests the parameter from the config engine by the command.
+// Returns the reply parameters.
+//
+// In the strict mode, the missing or not convertable parameter returns *ParamError.
+func (c *Client) param(command string, name string) (key_value.KeyValue, error) {
 	if c == nil || c.socket == nil {
-		return "", fmt.Errorf("nil or closed")
+		return nil, fmt.Errorf("nil or closed")
 	}
 
 	req := message.Request{
-		Command:    handler.StringParam,
-		Parameters: key_value.New().Set("name", name),
+		Command:    command,
+		Parameters: c.scope(key_value.New().Set("name", name).Set("strict", c.strict)),
 	}
 
 	rep, err := c.socket.Request(&req)
 	if err != nil {
-		return "", fmt.Errorf("socket.Request('%s'): %w", handler.StringParam, err)
+		return nil, fmt.Errorf("socket.Request('%s'): %w", command, err)
 	}
 
 	if !rep.IsOK() {
-		return "", fmt.Errorf("replied an error: %s", rep.ErrorMessage())
+		return nil, replyError(name, rep)
+	}
+
+	return rep.ReplyParameters(), nil
+}
+
+// String parameter from config engine
+func (c *Client) String(name string) (string, error) {
+	params, err := c.param(handler.StringParam, name)
+	if err != nil {
+		return "", err
 	}
 
-	value, err := rep.ReplyParameters().StringValue("value")
+	value, err := params.StringValue("value")
 	if err != nil {
 		return "", fmt.Errorf("rep.Parameters.StringValue('value'): %v", err)
 	}
@@ -313,60 +429,138 @@ func (c *Client) String(name string) (string, error) {
 
 // Uint64 parameter from config engine
 func (c *Client) Uint64(name string) (uint64, error) {
-	if c == nil || c.socket == nil {
-		return 0, fmt.Errorf("nil or closed")
+	params, err := c.param(handler.Uint64Param, name)
+	if err != nil {
+		return 0, err
 	}
 
-	req := message.Request{
-		Command:    handler.Uint64Param,
-		Parameters: key_value.New().Set("name", name),
+	value, err := params.Uint64Value("value")
+	if err != nil {
+		return 0, fmt.Errorf("rep.Parameters.Uint64Value('value'): %v", err)
 	}
 
-	rep, err := c.socket.Request(&req)
+	return value, nil
+}
+
+// Bool parameter from config engine
+func (c *Client) Bool(name string) (bool, error) {
+	params, err := c.param(handler.BoolParam, name)
 	if err != nil {
-		return 0, fmt.Errorf("socket.Request('%s'): %w", handler.Uint64Param, err)
+		return false, err
 	}
 
-	if !rep.IsOK() {
-		return 0, fmt.Errorf("replied an error: %s", rep.ErrorMessage())
+	value, err := params.BoolValue("value")
+	if err != nil {
+		return false, fmt.Errorf("rep.Parameters.GetBoolean('value'): %v", err)
 	}
 
-	value, err := rep.ReplyParameters().Uint64Value("value")
+	return value, nil
+}
+
+// Float64 parameter from config engine
+func (c *Client) Float64(name string) (float64, error) {
+	params, err := c.param(handler.Float64Param, name)
 	if err != nil {
-		return 0, fmt.Errorf("rep.Parameters.Uint64Value('value'): %v", err)
+		return 0, err
+	}
+
+	value, err := params.Float64Value("value")
+	if err != nil {
+		return 0, fmt.Errorf("rep.Parameters.Float64Value('value'): %v", err)
 	}
 
 	return value, nil
 }
 
-// Bool parameter from config engine
-func (c *Client) Bool(name string) (bool, error) {
-	if c == nil || c.socket == nil {
-		return false, fmt.Errorf("nil or closed")
+// Int64 parameter from config engine
+func (c *Client) Int64(name string) (int64, error) {
+	params, err := c.param(handler.Int64Param, name)
+	if err != nil {
+		return 0, err
 	}
 
-	req := message.Request{
-		Command:    handler.BoolParam,
-		Parameters: key_value.New().Set("name", name),
+	valueStr, err := params.StringValue("value")
+	if err != nil {
+		return 0, fmt.Errorf("rep.Parameters.StringValue('value'): %v", err)
 	}
 
-	rep, err := c.socket.Request(&req)
+	value, err := strconv.ParseInt(valueStr, 10, 64)
 	if err != nil {
-		return false, fmt.Errorf("socket.Request('%s'): %w", handler.BoolParam, err)
+		return 0, fmt.Errorf("strconv.ParseInt('%s'): %v", valueStr, err)
 	}
 
-	if !rep.IsOK() {
-		return false, fmt.Errorf("replied an error: %s", rep.ErrorMessage())
+	return value, nil
+}
+
+// Duration parameter from config engine
+func (c *Client) Duration(name string) (time.Duration, error) {
+	params, err := c.param(handler.DurationParam, name)
+	if err != nil {
+		return 0, err
 	}
 
-	value, err := rep.ReplyParameters().BoolValue("value")
+	valueStr, err := params.StringValue("value")
 	if err != nil {
-		return false, fmt.Errorf("rep.Parameters.GetBoolean('value'): %v", err)
+		return 0, fmt.Errorf("rep.Parameters.StringValue('value'): %v", err)
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return 0, fmt.Errorf("time.ParseDuration('%s'): %v", valueStr, err)
 	}
 
 	return value, nil
 }
 
+// StringSlice parameter from config engine.
+// The parameter could be a JSON list or comma separated values.
+func (c *Client) StringSlice(name string) ([]string, error) {
+	params, err := c.param(handler.StringSliceParam, name)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := params.StringsValue("value")
+	if err != nil {
+		return nil, fmt.Errorf("rep.Parameters.StringsValue('value'): %v", err)
+	}
+
+	return value, nil
+}
+
+// StringMap parameter from config engine.
+// The parameter could be a JSON object or comma separated key=value pairs.
+func (c *Client) StringMap(name string) (map[string]string, error) {
+	params, err := c.param(handler.StringMapParam, name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := params.NestedValue("value")
+	if err != nil {
+		return nil, fmt.Errorf("rep.Parameters.NestedValue('value'): %v", err)
+	}
+
+	var value map[string]string
+	err = raw.Interface(&value)
+	if err != nil {
+		return nil, fmt.Errorf("raw.Interface: %v", err)
+	}
+
+	return value, nil
+}
+
+// withValue adds the value with its type into the request parameters.
+// The handler restores the Go type of the value, since the numbers are float64 in JSON.
+// The value of other types is sent as is.
+func withValue(params key_value.KeyValue, value interface{}) key_value.KeyValue {
+	kind, encoded, err := engine.EncodeKind(value)
+	if err != nil {
+		return params.Set("value", value)
+	}
+	return params.Set("value", encoded).Set("type", string(kind))
+}
+
 // SetDefault sets the default value
 func (c *Client) SetDefault(name string, value interface{}) error {
 	if c == nil || c.socket == nil {
@@ -375,17 +569,293 @@ func (c *Client) SetDefault(name string, value interface{}) error {
 
 	req := message.Request{
 		Command:    handler.SetDefaultParam,
-		Parameters: key_value.New().Set("name", name).Set("value", value),
+		Parameters: withValue(key_value.New().Set("name", name), value),
 	}
 
 	err := c.socket.Submit(&req)
 	if err != nil {
-		return fmt.Errorf("socket.Submit('%s'): %w", handler.ParamExist, err)
+		return fmt.Errorf("socket.Submit('%s'): %w", handler.SetDefaultParam, err)
+	}
+
+	return nil
+}
+
+// SetDefaultAck sets the default value and waits until the config engine sets it.
+// Unlike SetDefault, the following reads are guaranteed to see the default value,
+// and the error of the config engine is returned.
+func (c *Client) SetDefaultAck(name string, value interface{}) error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.SetDefaultParam,
+		Parameters: withValue(key_value.New().Set("name", name), value),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.SetDefaultParam, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
 	}
 
 	return nil
 }
 
+// SetDefaults sets the default values at once and waits until the config engine sets them.
+// The Go types of the values are preserved, see engine.EncodeKind.
+//
+// Returns the names of the parameters that are skipped, since they are set already.
+// Returns *engine.ValidationError that lists every parameter that could not be set.
+// In that case, none of the parameters is set.
+func (c *Client) SetDefaults(params key_value.KeyValue) ([]string, error) {
+	if c == nil || c.socket == nil {
+		return nil, fmt.Errorf("nil or closed")
+	}
+
+	values := key_value.New()
+	types := key_value.New()
+	for name, value := range params {
+		kind, encoded, err := engine.EncodeKind(value)
+		if err != nil {
+			values.Set(name, value)
+			continue
+		}
+		values.Set(name, encoded)
+		types.Set(name, string(kind))
+	}
+	reqParams := key_value.New().Set("params", values)
+	if len(types) > 0 {
+		reqParams.Set("types", types)
+	}
+
+	req := message.Request{
+		Command:    handler.SetDefaultParams,
+		Parameters: reqParams,
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return nil, fmt.Errorf("socket.Request('%s'): %w", handler.SetDefaultParams, err)
+	}
+
+	if !reply.IsOK() {
+		return nil, fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	if err := problemsError(reply.ReplyParameters()); err != nil {
+		return nil, err
+	}
+
+	skipped, err := reply.ReplyParameters().StringsValue("skipped")
+	if err != nil {
+		return nil, fmt.Errorf("reply.Parameters.StringsValue('skipped'): %w", err)
+	}
+
+	return skipped, nil
+}
+
+// Set overrides the parameter at runtime.
+// The override has the highest precedence and persists after the restart of the config handler.
+func (c *Client) Set(name string, value interface{}) error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.SetParam,
+		Parameters: withValue(key_value.New().Set("name", name), value),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.SetParam, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	return nil
+}
+
+// Unset removes the runtime override of the parameter.
+// Then the parameter from other sources is used.
+//
+// Returns false if the parameter was not overridden.
+func (c *Client) Unset(name string) (bool, error) {
+	if c == nil || c.socket == nil {
+		return false, fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.UnsetParam,
+		Parameters: key_value.New().Set("name", name),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return false, fmt.Errorf("socket.Request('%s'): %w", handler.UnsetParam, err)
+	}
+
+	if !reply.IsOK() {
+		return false, fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	unset, err := reply.ReplyParameters().BoolValue("unset")
+	if err != nil {
+		return false, fmt.Errorf("reply.Parameters.BoolValue('unset'): %w", err)
+	}
+
+	return unset, nil
+}
+
+// RegisterSchema registers the parameter schemas in the config engine.
+// The default values of the schemas are set as the default parameters.
+func (c *Client) RegisterSchema(schemas ...*engine.Schema) error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.RegisterSchema,
+		Parameters: key_value.New().Set("schemas", schemas),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.RegisterSchema, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	return nil
+}
+
+// Validate the registered parameters in the config engine.
+// Returns *engine.ValidationError that lists every problem.
+func (c *Client) Validate() error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.ValidateParams,
+		Parameters: key_value.New(),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.ValidateParams, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	return problemsError(reply.ReplyParameters())
+}
+
+// problemsError returns *engine.ValidationError of the 'problems' list in the reply parameters.
+// Returns nil if there are no problems.
+func problemsError(params key_value.KeyValue) error {
+	rawProblems, err := params.NestedListValue("problems")
+	if err != nil {
+		return fmt.Errorf("reply.Parameters.NestedListValue('problems'): %w", err)
+	}
+
+	if len(rawProblems) == 0 {
+		return nil
+	}
+
+	problems := make([]*engine.Problem, len(rawProblems))
+	for i, raw := range rawProblems {
+		var problem engine.Problem
+		if err := raw.Interface(&problem); err != nil {
+			return fmt.Errorf("problems[%d].Interface: %w", i, err)
+		}
+		problems[i] = &problem
+	}
+
+	return &engine.ValidationError{Problems: problems}
+}
+
+// Source returns the parameter value as a string and the source where it came from.
+func (c *Client) Source(name string) (string, *engine.Source, error) {
+	if c == nil || c.socket == nil {
+		return "", nil, fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.ParamSource,
+		Parameters: key_value.New().Set("name", name),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return "", nil, fmt.Errorf("socket.Request('%s'): %w", handler.ParamSource, err)
+	}
+
+	if !reply.IsOK() {
+		return "", nil, fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	value, err := reply.ReplyParameters().StringValue("value")
+	if err != nil {
+		return "", nil, fmt.Errorf("reply.Parameters.StringValue('value'): %w", err)
+	}
+
+	raw, err := reply.ReplyParameters().NestedValue("source")
+	if err != nil {
+		return "", nil, fmt.Errorf("reply.Parameters.NestedValue('source'): %w", err)
+	}
+
+	var source engine.Source
+	if err := raw.Interface(&source); err != nil {
+		return "", nil, fmt.Errorf("raw.Interface: %w", err)
+	}
+
+	return value, &source, nil
+}
+
+// Sources returns the source of every parameter by the parameter name.
+func (c *Client) Sources() (map[string]*engine.Source, error) {
+	if c == nil || c.socket == nil {
+		return nil, fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.ParamSources,
+		Parameters: key_value.New(),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return nil, fmt.Errorf("socket.Request('%s'): %w", handler.ParamSources, err)
+	}
+
+	if !reply.IsOK() {
+		return nil, fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	raw, err := reply.ReplyParameters().NestedValue("sources")
+	if err != nil {
+		return nil, fmt.Errorf("reply.Parameters.NestedValue('sources'): %w", err)
+	}
+
+	var sources map[string]*engine.Source
+	if err := raw.Interface(&sources); err != nil {
+		return nil, fmt.Errorf("raw.Interface: %w", err)
+	}
+
+	return sources, nil
+}
+
 // ServiceExist checks whether the service exists or not
 func (c *Client) ServiceExist(id string) (bool, error) {
 	return c.serviceExist("id", id)
@@ -423,3 +893,136 @@ func (c *Client) serviceExist(name string, value string) (bool, error) {
 
 	return exist, nil
 }
+
+// Export returns the effective parameters of the config engine in the given format.
+// The values of the secret parameters are redacted.
+//
+// If the secret patterns are not given, then the patterns of the config engine are used.
+func (c *Client) Export(format engine.ExportFormat, secretPatterns ...string) (string, error) {
+	if c == nil || c.socket == nil {
+		return "", fmt.Errorf("nil or closed")
+	}
+
+	params := key_value.New().Set("format", string(format))
+	if len(secretPatterns) > 0 {
+		params.Set("secret_patterns", secretPatterns)
+	}
+	req := message.Request{
+		Command:    handler.ExportParams,
+		Parameters: params,
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return "", fmt.Errorf("socket.Request('%s'): %w", handler.ExportParams, err)
+	}
+
+	if !reply.IsOK() {
+		return "", fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	content, err := reply.ReplyParameters().StringValue("content")
+	if err != nil {
+		return "", fmt.Errorf("reply.Parameters.StringValue('content'): %w", err)
+	}
+
+	return content, nil
+}
+
+// Unmarshal fills the target struct with the parameters of the config engine.
+// The struct fields are described by the `config`, `default`, `required` and `prefix` tags.
+// See engine.Bind for the details.
+//
+// Returns *engine.ValidationError that lists every field that could not be bound.
+func (c *Client) Unmarshal(target interface{}) error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	names, err := engine.BindNames(target)
+	if err != nil {
+		return fmt.Errorf("engine.BindNames: %w", err)
+	}
+
+	req := message.Request{
+		Command:    handler.BindParams,
+		Parameters: c.scope(key_value.New().Set("names", names)),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.BindParams, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	params, err := reply.ReplyParameters().NestedValue("params")
+	if err != nil {
+		return fmt.Errorf("reply.Parameters.NestedValue('params'): %w", err)
+	}
+
+	return engine.BindFunc(target, func(name string) (interface{}, error) {
+		return params[name], nil
+	})
+}
+
+// Tree returns the parameters under the prefix as the nested key-value.
+// For example, Tree("cache") returns CACHE__HOST and CACHE__PORT as {"host": ..., "port": ...}.
+// The empty prefix returns all parameters.
+func (c *Client) Tree(prefix string) (key_value.KeyValue, error) {
+	if c == nil || c.socket == nil {
+		return nil, fmt.Errorf("nil or closed")
+	}
+
+	req := message.Request{
+		Command:    handler.ParamTree,
+		Parameters: key_value.New().Set("prefix", prefix),
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return nil, fmt.Errorf("socket.Request('%s'): %w", handler.ParamTree, err)
+	}
+
+	if !reply.IsOK() {
+		return nil, fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	tree, err := reply.ReplyParameters().NestedValue("tree")
+	if err != nil {
+		return nil, fmt.Errorf("reply.Parameters.NestedValue('tree'): %w", err)
+	}
+
+	return tree, nil
+}
+
+// SetServiceParam sets the custom parameter of the service.
+// If the handlerId is not empty, then the parameter is set for the handler of the service.
+// The parameter is persisted in the app configuration.
+func (c *Client) SetServiceParam(serviceId string, handlerId string, name string, value interface{}) error {
+	if c == nil || c.socket == nil {
+		return fmt.Errorf("nil or closed")
+	}
+
+	params := withValue(key_value.New().Set("service", serviceId).Set("name", name), value)
+	if len(handlerId) > 0 {
+		params.Set("handler", handlerId)
+	}
+	req := message.Request{
+		Command:    handler.SetServiceParam,
+		Parameters: params,
+	}
+
+	reply, err := c.socket.Request(&req)
+	if err != nil {
+		return fmt.Errorf("socket.Request('%s'): %w", handler.SetServiceParam, err)
+	}
+
+	if !reply.IsOK() {
+		return fmt.Errorf("reply.Message: %s", reply.ErrorMessage())
+	}
+
+	return nil
+}
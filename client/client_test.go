@@ -2,6 +2,7 @@ package client
 
 import (
 	"github.com/ahmetson/config-lib/app"
+	"github.com/ahmetson/config-lib/engine"
 	"github.com/ahmetson/config-lib/handler"
 	"github.com/ahmetson/config-lib/service"
 	handlerConfig "github.com/ahmetson/handler-lib/config"
@@ -50,6 +51,11 @@ func (test *TestClientSuite) setupHandler() {
 	test.handler.Engine.Set("bool", true)
 	test.handler.Engine.Set("string", "hello world")
 	test.handler.Engine.Set("uint64", uint64(123))
+	test.handler.Engine.Set("float64", "75.321")
+	test.handler.Engine.Set("int64", "-5")
+	test.handler.Engine.Set("duration", "1m30s")
+	test.handler.Engine.Set("string_slice", "a, b")
+	test.handler.Engine.Set("string_map", `{"a": "1"}`)
 }
 
 func (test *TestClientSuite) setupClient() {
@@ -94,6 +100,7 @@ func (test *TestClientSuite) TearDownTest() {
 	}
 
 	test.deleteYaml(test.execPath, "app")
+	test.deleteYaml(test.execPath, "app.overrides")
 }
 
 func (test *TestClientSuite) createYaml(dir string, name string) {
@@ -123,6 +130,20 @@ func (test *TestClientSuite) deleteYaml(dir string, name string) {
 
 	filePath := filepath.Join(dir, name+".yml")
 
+	// the backups are written by app.Write
+	backups, err := app.Backups(filePath)
+	s().NoError(err)
+	for _, backupPath := range backups {
+		s().NoError(os.Remove(backupPath))
+	}
+
+	// the lock file is created by app.Write
+	lockExist, err := path.FileExist(app.LockPath(filePath))
+	s().NoError(err)
+	if lockExist {
+		s().NoError(os.Remove(app.LockPath(filePath)))
+	}
+
 	exist, err := path.FileExist(filePath)
 	s().NoError(err)
 
@@ -216,6 +237,31 @@ func (test *TestClientSuite) Test_14_GetParam() {
 	valueUint64, err := test.client.Uint64("uint64")
 	s().NoError(err)
 	s().NotZero(valueUint64)
+
+	valueFloat64, err := test.client.Float64("float64")
+	s().NoError(err)
+	s().Equal(75.321, valueFloat64)
+
+	valueInt64, err := test.client.Int64("int64")
+	s().NoError(err)
+	s().Equal(int64(-5), valueInt64)
+
+	valueDuration, err := test.client.Duration("duration")
+	s().NoError(err)
+	s().Equal(time.Second*90, valueDuration)
+
+	valueSlice, err := test.client.StringSlice("string_slice")
+	s().NoError(err)
+	s().Equal([]string{"a", "b"}, valueSlice)
+
+	valueMap, err := test.client.StringMap("string_map")
+	s().NoError(err)
+	s().Equal(map[string]string{"a": "1"}, valueMap)
+
+	// the missing parameter returns the empty value
+	valueSlice, err = test.client.StringSlice("not_exist")
+	s().NoError(err)
+	s().Empty(valueSlice)
 }
 
 // Test_15_GenerateHandler generate a handler
@@ -299,6 +345,310 @@ func (test *TestClientSuite) Test_17_GenerateService() {
 	s().NoError(err)
 }
 
+// Test_18_Strict tests the parameter reads in the strict mode
+func (test *TestClientSuite) Test_18_Strict() {
+	s := test.Require
+
+	// By default, the missing parameter returns a zero value
+	value, err := test.client.Uint64("not_exist")
+	s().NoError(err)
+	s().Zero(value)
+
+	test.client.Strict(true)
+
+	// The missing parameter must fail
+	_, err = test.client.Uint64("not_exist")
+	s().Error(err)
+	s().ErrorIs(err, engine.ErrNotFound)
+	var paramErr *ParamError
+	s().ErrorAs(err, &paramErr)
+	s().Equal("not_exist", paramErr.Name)
+
+	// The parameter that can't be converted must fail
+	_, err = test.client.Uint64("string")
+	s().ErrorIs(err, engine.ErrConversion)
+
+	// The valid parameter is returned
+	value, err = test.client.Uint64("uint64")
+	s().NoError(err)
+	s().Equal(uint64(123), value)
+
+	test.client.Strict(false)
+
+	// The strict mode of a single read
+	_, err = test.client.WithStrict(true).Uint64("not_exist")
+	s().ErrorIs(err, engine.ErrNotFound)
+	value, err = test.client.Uint64("not_exist")
+	s().NoError(err)
+	s().Zero(value)
+}
+
+// Test_19_Schema tests the registration and validation of the parameter schemas
+func (test *TestClientSuite) Test_19_Schema() {
+	s := test.Require
+
+	// the invalid schema must fail
+	err := test.client.RegisterSchema(&engine.Schema{Key: "PORT", Type: "number"})
+	s().Error(err)
+
+	err = test.client.RegisterSchema(
+		&engine.Schema{Key: "PORT", Type: engine.Uint64Kind, Default: 8080},
+		&engine.Schema{Key: "REQUIRED_KEY", Type: engine.StringKind, Required: true},
+		&engine.Schema{Key: "string", Type: engine.Uint64Kind},
+	)
+	s().NoError(err)
+
+	// the default value is set
+	value, err := test.client.Uint64("PORT")
+	s().NoError(err)
+	s().Equal(uint64(8080), value)
+
+	// the missing and invalid parameters are reported at once
+	err = test.client.Validate()
+	s().Error(err)
+	validationErr, ok := err.(*engine.ValidationError)
+	s().True(ok)
+	s().Len(validationErr.Problems, 2)
+}
+
+// Test_20_Source tests the source of the parameters
+func (test *TestClientSuite) Test_20_Source() {
+	s := test.Require
+
+	value, source, err := test.client.Source("string")
+	s().NoError(err)
+	s().Equal("hello world", value)
+	s().Equal(engine.SetSource, source.Kind)
+
+	// the missing parameter has no source
+	_, _, err = test.client.Source("not_exist")
+	s().Error(err)
+
+	sources, err := test.client.Sources()
+	s().NoError(err)
+	s().Contains(sources, "string")
+	s().Equal(engine.DefaultSource, sources[app.EnvConfigName].Kind)
+}
+
+// Test_21_Set tests overriding the parameters at runtime
+func (test *TestClientSuite) Test_21_Set() {
+	s := test.Require
+
+	s().NoError(test.client.Set("string", "overridden"))
+	value, err := test.client.String("string")
+	s().NoError(err)
+	s().Equal("overridden", value)
+
+	// the overrides are persisted next to the app file
+	exist, err := path.FileExist(app.OverridesPath(filepath.Join(test.execPath, "app.yml")))
+	s().NoError(err)
+	s().True(exist)
+
+	unset, err := test.client.Unset("string")
+	s().NoError(err)
+	s().True(unset)
+
+	// the parameter is not overridden anymore
+	unset, err = test.client.Unset("string")
+	s().NoError(err)
+	s().False(unset)
+
+	value, err = test.client.String("string")
+	s().NoError(err)
+	s().Empty(value)
+}
+
+// Test_22_Export tests the export of the effective parameters
+func (test *TestClientSuite) Test_22_Export() {
+	s := test.Require
+
+	test.handler.Engine.Set("API_KEY", "abc")
+
+	content, err := test.client.Export(engine.EnvFormat)
+	s().NoError(err)
+	s().Contains(content, `STRING="hello world"`)
+	s().Contains(content, `API_KEY="`+engine.Redacted+`"`)
+
+	content, err = test.client.Export(engine.JsonFormat, "STRING")
+	s().NoError(err)
+	s().Contains(content, `"API_KEY": "abc"`)
+	s().NotContains(content, "hello world")
+
+	_, err = test.client.Export("xml")
+	s().Error(err)
+}
+
+// Test_23_Secret tests the secret references resolved by the secret provider
+func (test *TestClientSuite) Test_23_Secret() {
+	s := test.Require
+
+	dir := test.T().TempDir()
+	s().NoError(os.WriteFile(filepath.Join(dir, "password"), []byte("qwerty"), 0600))
+	provider, err := engine.NewDirSecretProvider(dir)
+	s().NoError(err)
+
+	dev, ok := test.handler.Engine.(*engine.Dev)
+	s().True(ok)
+	s().NoError(dev.SetSecretProvider(provider, time.Minute))
+	test.handler.Engine.Set("DB_PASSWORD", "secret://password")
+
+	value, err := test.client.String("DB_PASSWORD")
+	s().NoError(err)
+	s().Equal("qwerty", value)
+
+	// the secret is not exported
+	content, err := test.client.Export(engine.EnvFormat)
+	s().NoError(err)
+	s().NotContains(content, "qwerty")
+}
+
+// Test_24_Unmarshal tests binding the parameters into the struct
+func (test *TestClientSuite) Test_24_Unmarshal() {
+	s := test.Require
+
+	var cfg struct {
+		Flag     bool              `config:"bool"`
+		Str      string            `config:"string"`
+		Number   uint64            `config:"uint64"`
+		Float    float64           `config:"float64"`
+		Duration time.Duration     `config:"duration"`
+		Slice    []string          `config:"string_slice"`
+		Map      map[string]string `config:"string_map"`
+		Port     uint16            `config:"PORT" default:"8080"`
+	}
+	s().NoError(test.client.Unmarshal(&cfg))
+	s().True(cfg.Flag)
+	s().Equal("hello world", cfg.Str)
+	s().Equal(uint64(123), cfg.Number)
+	s().Equal(75.321, cfg.Float)
+	s().Equal(time.Second*90, cfg.Duration)
+	s().Equal([]string{"a", "b"}, cfg.Slice)
+	s().Equal(map[string]string{"a": "1"}, cfg.Map)
+	s().Equal(uint16(8080), cfg.Port)
+
+	// all problems are returned at once
+	var invalid struct {
+		Str   uint64 `config:"string"`
+		Token string `config:"TOKEN" required:"true"`
+	}
+	err := test.client.Unmarshal(&invalid)
+	s().Error(err)
+	validationErr, ok := err.(*engine.ValidationError)
+	s().True(ok)
+	s().Len(validationErr.Problems, 2)
+}
+
+// Test_25_Tree tests fetching the nested parameters at once
+func (test *TestClientSuite) Test_25_Tree() {
+	s := test.Require
+
+	test.handler.Engine.Set("CACHE__HOST", "localhost")
+	test.handler.Engine.Set("CACHE__REPLICA__HOST", "replica")
+
+	tree, err := test.client.Tree("cache")
+	s().NoError(err)
+	s().Equal("localhost", tree["host"])
+
+	replica, err := tree.NestedValue("replica")
+	s().NoError(err)
+	host, err := replica.StringValue("host")
+	s().NoError(err)
+	s().Equal("replica", host)
+
+	tree, err = test.client.Tree("not_exist")
+	s().NoError(err)
+	s().Empty(tree)
+}
+
+// Test_26_ServiceParams tests the custom parameters of the service
+func (test *TestClientSuite) Test_26_ServiceParams() {
+	s := test.Require
+
+	s().NoError(test.client.SetServiceParam(test.serviceId, "", "string", "service value"))
+	// the handler must exist in the service
+	s().Error(test.client.SetServiceParam(test.serviceId, "not_exist", "string", "handler value"))
+	s().Error(test.client.SetServiceParam("not_exist", "", "string", "service value"))
+
+	test.client.Scope(test.serviceId, "")
+	value, err := test.client.String("string")
+	s().NoError(err)
+	s().Equal("service value", value)
+
+	// falls back to the global parameter
+	flag, err := test.client.Bool("bool")
+	s().NoError(err)
+	s().True(flag)
+
+	test.client.Scope("not_exist", "")
+	_, err = test.client.String("string")
+	s().Error(err)
+
+	test.client.Scope("", "")
+	value, err = test.client.String("string")
+	s().NoError(err)
+	s().Equal("hello world", value)
+
+	// the parameter is persisted with the service
+	appConfig := app.New()
+	s().NoError(app.Read(filepath.Join(test.execPath, "app.yml"), appConfig))
+	param, ok := appConfig.Service(test.serviceId).Param("string")
+	s().True(ok)
+	s().Equal("service value", param)
+}
+
+// Test_27_SetDefaults tests the acknowledged default parameters
+func (test *TestClientSuite) Test_27_SetDefaults() {
+	s := test.Require
+
+	// the following read sees the default
+	s().NoError(test.client.SetDefaultAck("APP_TIMEOUT", time.Second))
+	value, err := test.client.Duration("APP_TIMEOUT")
+	s().NoError(err)
+	s().Equal(time.Second, value)
+
+	skipped, err := test.client.SetDefaults(key_value.New().Set("APP_PORT", uint64(8080)).Set("APP_HOSTS", []string{"a", "b"}))
+	s().NoError(err)
+	s().Empty(skipped)
+	s().Equal(uint64(8080), test.handler.Engine.Get("APP_PORT"))
+	hosts, err := test.client.StringSlice("APP_HOSTS")
+	s().NoError(err)
+	s().Equal([]string{"a", "b"}, hosts)
+}
+
+// Test_28_RestoreApp tests restoring the app configuration from the backup
+func (test *TestClientSuite) Test_28_RestoreApp() {
+	s := test.Require
+
+	id := test.serviceId + "_2"
+	url := test.serviceUrl + "_2"
+	sampleManager, err := service.NewManager(id, url)
+	s().NoError(err)
+	s().NoError(test.client.SetService(service.New(id, url, service.IndependentType, sampleManager)))
+	exist, err := test.client.ServiceExist(id)
+	s().NoError(err)
+	s().True(exist)
+
+	// the version before setting the service
+	s().NoError(test.client.RestoreApp(1))
+	exist, err = test.client.ServiceExist(id)
+	s().NoError(err)
+	s().False(exist)
+	exist, err = test.client.ServiceExist(test.serviceId)
+	s().NoError(err)
+	s().True(exist)
+
+	// the invalid backup is not restored
+	filePath := filepath.Join(test.execPath, "app.yml")
+	invalid := []byte("services:\n  - id: duplicate\n    url: url_1\n  - id: duplicate\n    url: url_2\n")
+	s().NoError(os.WriteFile(app.BackupPath(filePath, 1), invalid, 0644))
+	s().Error(test.client.RestoreApp(1))
+	exist, err = test.client.ServiceExist(test.serviceId)
+	s().NoError(err)
+	s().True(exist)
+
+	s().Error(test.client.RestoreApp(0))
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestHandler(t *testing.T) {
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"fmt"
+	"github.com/ahmetson/config-lib/engine"
+	"github.com/ahmetson/config-lib/handler"
+	"github.com/ahmetson/datatype-lib/message"
+)
+
+// ParamError is returned by the parameter reads in the strict mode.
+// Use errors.Is with engine.ErrNotFound, engine.ErrConversion or engine.ErrInterpolation to check the reason.
+type ParamError struct {
+	Name    string // parameter name
+	Err     error  // engine.ErrNotFound, engine.ErrConversion or engine.ErrInterpolation
+	Message string // the error message replied by the handler
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("param('%s'): %s", e.Name, e.Message)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// codeErrors are the reasons of the failed parameter reads by the replied 'code'
+var codeErrors = map[string]error{
+	handler.NotFoundCode:      engine.ErrNotFound,
+	handler.ConversionCode:    engine.ErrConversion,
+	handler.InterpolationCode: engine.ErrInterpolation,
+}
+
+// replyError converts the failed reply of the parameter request to the error.
+// If the handler replied with the code of the strict mode failure, then returns *ParamError.
+func replyError(name string, rep message.ReplyInterface) error {
+	code, _ := rep.ReplyParameters().StringValue("code")
+	if err, ok := codeErrors[code]; ok {
+		return &ParamError{Name: name, Err: err, Message: rep.ErrorMessage()}
+	}
+
+	return fmt.Errorf("replied an error: %s", rep.ErrorMessage())
+}
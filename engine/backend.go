@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+//
+// Remote key-value stores, for example etcd or consul.
+//
+// The backend keys are converted to the parameter names:
+// the prefix is removed, and the slashes are replaced by the dots.
+// For example, the 'app/db/host' key with the 'app/' prefix is the 'db.host' parameter.
+//
+
+// Backend of the remote key-value store
+type Backend interface {
+	// Get returns the value of the key.
+	// Returns false if the key doesn't exist.
+	Get(key string) (string, bool, error)
+	// List returns the values of the keys starting with the prefix by the key.
+	List(prefix string) (map[string]string, error)
+	// Watch calls the handler when the key starting with the prefix is changed or deleted.
+	// The changes are compared to the baseline returned by List,
+	// so the changes made after the listing but before the watching are not lost.
+	// It doesn't block, watching stops when the context is canceled.
+	Watch(ctx context.Context, prefix string, baseline map[string]string, handler func(BackendEvent)) error
+}
+
+// BackendEvent is the change of the key in the Backend
+type BackendEvent struct {
+	Key     string
+	Value   string
+	Deleted bool
+}
+
+// remoteValue is the parameter loaded from the Backend
+type remoteValue struct {
+	name   string
+	value  string
+	source *Source
+}
+
+// BackendKey returns the parameter name of the backend key
+func BackendKey(key string, prefix string) string {
+	name := strings.Trim(strings.TrimPrefix(key, prefix), "/")
+	return strings.ReplaceAll(name, "/", ".")
+}
+
+// AddBackend loads the parameters with the prefix from the backend
+// and keeps them updated until the context is canceled.
+//
+// The backend parameters overwrite the default parameters,
+// but the parameter files, environment variables, flags and overrides overwrite them.
+// If multiple backends have the same parameter, the latter backend is used.
+//
+// The changes in the backend are passed to the Watch handlers.
+func (config *Dev) AddBackend(ctx context.Context, backend Backend, prefix string) error {
+	if backend == nil {
+		return fmt.Errorf("backend is nil")
+	}
+
+	values, err := backend.List(prefix)
+	if err != nil {
+		return fmt.Errorf("backend.List('%s'): %w", prefix, err)
+	}
+	for key, value := range values {
+		config.setRemote(key, prefix, value, false)
+	}
+
+	err = backend.Watch(ctx, prefix, values, func(event BackendEvent) {
+		config.setRemote(event.Key, prefix, event.Value, event.Deleted)
+	})
+	if err != nil {
+		return fmt.Errorf("backend.Watch('%s'): %w", prefix, err)
+	}
+
+	return nil
+}
+
+// setRemote updates the parameter loaded from the backend and notifies the watchers
+func (config *Dev) setRemote(backendKey string, prefix string, value string, deleted bool) {
+	name := BackendKey(backendKey, prefix)
+	if len(name) == 0 {
+		return
+	}
+	key := strings.ToLower(name)
+
+	previous := config.Get(name)
+	config.mu.Lock()
+	if deleted {
+		delete(config.remote, key)
+	} else {
+		config.remote[key] = &remoteValue{
+			name:   name,
+			value:  value,
+			source: &Source{Kind: RemoteSource, Path: backendKey},
+		}
+	}
+	config.mu.Unlock()
+
+	config.notify(name, previous)
+}
+
+// remoteParam returns the parameter loaded from the backend.
+// Returns nil if the parameter is set in the local source with the higher precedence.
+//
+// The caller must hold the lock.
+func (config *Dev) remoteParam(key string) *remoteValue {
+	value, ok := config.remote[key]
+	if !ok {
+		return nil
+	}
+
+	if _, ok := config.envName(key); ok {
+		return nil
+	}
+	if _, ok := config.fileKeys[key]; ok {
+		return nil
+	}
+
+	return value
+}
@@ -0,0 +1,292 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//
+// Binding of the parameters into the Go structs.
+//
+// The struct fields are described by the tags:
+//
+//	type Config struct {
+//		Port    uint64        `config:"DB_PORT" default:"5432" required:"true"`
+//		Timeout time.Duration `config:"TIMEOUT" default:"5s"`
+//		Hosts   []string      `config:"HOSTS"`
+//		Cache   CacheConfig   `prefix:"CACHE_"`
+//	}
+//
+// The config tag is the parameter name. The fields without the config tag are skipped.
+// The nested structs are bound with the fields prefixed by the prefix tag.
+// The missing parameter keeps the field value, unless the default tag is set.
+// The missing required parameter without the default tag is an error.
+//
+
+const (
+	// BindTag is the struct tag with the parameter name
+	BindTag = "config"
+	// DefaultTag is the struct tag with the default value of the parameter
+	DefaultTag = "default"
+	// RequiredTag is the struct tag that marks the parameter as required
+	RequiredTag = "required"
+	// PrefixTag is the struct tag with the prefix of the nested struct parameters
+	PrefixTag = "prefix"
+)
+
+// durationType is bound by ToDuration rather than as an integer
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Bind fills the target struct with the parameters of the engine.
+// The target must be a pointer to a struct.
+//
+// Returns *ValidationError that lists every field that could not be bound.
+func Bind(configEngine Interface, target interface{}) error {
+	return BindFunc(target, func(name string) (interface{}, error) {
+		return configEngine.Get(name), nil
+	})
+}
+
+// Bind fills the target struct with the interpolated parameters.
+// The target must be a pointer to a struct.
+//
+// Returns *ValidationError that lists every field that could not be bound.
+func (config *Dev) Bind(target interface{}) error {
+	return BindFunc(target, config.Interpolate)
+}
+
+// BindFunc fills the target struct with the parameters returned by the lookup.
+// The lookup returns nil if the parameter is not set.
+//
+// Returns *ValidationError that lists every field that could not be bound.
+func BindFunc(target interface{}, lookup func(name string) (interface{}, error)) error {
+	value, err := structValue(target)
+	if err != nil {
+		return err
+	}
+
+	problems := bindStruct(value, "", lookup)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// BindNames returns the parameter names of the target struct fields.
+// The target must be a pointer to a struct.
+func BindNames(target interface{}) ([]string, error) {
+	value, err := structValue(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return bindNames(value.Type(), ""), nil
+}
+
+// structValue returns the struct that the target points to
+func structValue(target interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return reflect.Value{}, fmt.Errorf("target must be a non-nil pointer to a struct, given %T", target)
+	}
+	if value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("target must be a pointer to a struct, given %T", target)
+	}
+
+	return value.Elem(), nil
+}
+
+// nestedStruct returns the struct type of the field that is bound as the nested struct.
+// Returns nil if the field is not a nested struct.
+func nestedStruct(field reflect.StructField) reflect.Type {
+	if _, ok := field.Tag.Lookup(BindTag); ok {
+		return nil
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.Struct || fieldType == reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+
+	return fieldType
+}
+
+// bindNames returns the parameter names of the struct fields including the nested structs
+func bindNames(structType reflect.Type, prefix string) []string {
+	names := make([]string, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if nested := nestedStruct(field); nested != nil {
+			names = append(names, bindNames(nested, prefix+field.Tag.Get(PrefixTag))...)
+			continue
+		}
+
+		name := field.Tag.Get(BindTag)
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		names = append(names, prefix+name)
+	}
+
+	return names
+}
+
+// bindStruct fills the struct fields.
+// Returns the problems of all fields that could not be bound.
+func bindStruct(value reflect.Value, prefix string, lookup func(name string) (interface{}, error)) []*Problem {
+	problems := make([]*Problem, 0)
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if nested := nestedStruct(field); nested != nil {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(nested))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			problems = append(problems, bindStruct(fieldValue, prefix+field.Tag.Get(PrefixTag), lookup)...)
+			continue
+		}
+
+		name := field.Tag.Get(BindTag)
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		name = prefix + name
+
+		raw, err := lookup(name)
+		if err != nil {
+			problems = append(problems, &Problem{Key: name, Reason: err.Error()})
+			continue
+		}
+		if raw == nil {
+			defaultValue, ok := field.Tag.Lookup(DefaultTag)
+			if !ok {
+				if field.Tag.Get(RequiredTag) == "true" {
+					problems = append(problems, &Problem{Key: name, Reason: "required parameter is missing"})
+				}
+				continue
+			}
+			raw = defaultValue
+		}
+
+		if err := setField(fieldValue, raw); err != nil {
+			problems = append(problems, &Problem{Key: name, Reason: fmt.Sprintf("field %s: %v", field.Name, err)})
+		}
+	}
+
+	return problems
+}
+
+// setField converts the raw parameter to the type of the field and sets it.
+func setField(field reflect.Value, raw interface{}) error {
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := setField(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	if field.Type() == durationType {
+		value, err := ToDuration(raw)
+		if err != nil {
+			return fmt.Errorf("not a duration: %w", err)
+		}
+		field.SetInt(int64(value))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		value, err := ToString(raw)
+		if err != nil {
+			return fmt.Errorf("not a string: %w", err)
+		}
+		field.SetString(value)
+	case reflect.Bool:
+		value, err := ToBool(raw)
+		if err != nil {
+			return fmt.Errorf("not a bool: %w", err)
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := ToInt64(raw)
+		if err != nil {
+			return fmt.Errorf("not an integer: %w", err)
+		}
+		if field.OverflowInt(value) {
+			return fmt.Errorf("%d overflows %s", value, field.Type())
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := ToUint64(raw)
+		if err != nil {
+			return fmt.Errorf("not an unsigned integer: %w", err)
+		}
+		if field.OverflowUint(value) {
+			return fmt.Errorf("%d overflows %s", value, field.Type())
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := ToFloat64(raw)
+		if err != nil {
+			return fmt.Errorf("not a float: %w", err)
+		}
+		if field.OverflowFloat(value) {
+			return fmt.Errorf("%v overflows %s", value, field.Type())
+		}
+		field.SetFloat(value)
+	case reflect.Slice:
+		elements, err := ToStringSlice(raw)
+		if err != nil {
+			return fmt.Errorf("not a list: %w", err)
+		}
+		slice := reflect.MakeSlice(field.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := setField(slice.Index(i), strings.TrimSpace(element)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		if field.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s is not supported, the map key must be a string", field.Type())
+		}
+		object, err := ToStringMap(raw)
+		if err != nil {
+			return fmt.Errorf("not a map: %w", err)
+		}
+		m := reflect.MakeMapWithSize(field.Type(), len(object))
+		for key, element := range object {
+			value := reflect.New(field.Type().Elem()).Elem()
+			if err := setField(value, element); err != nil {
+				return fmt.Errorf("element '%s': %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), value)
+		}
+		field.Set(m)
+	default:
+		return fmt.Errorf("%s is not supported", field.Type())
+	}
+
+	return nil
+}
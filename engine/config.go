@@ -3,14 +3,28 @@
 // The config features:
 //   - reads the command line arguments for the app such as authentication enabled or not.
 //   - automatically loads the environment variables files.
+//   - loads the parameter files in .toml, .json, .ini or .yaml formats.
 //   - Allows setting default variables if user didn't define them.
+//
+// The parameters are merged in the following order, the latter overwrites the former:
+//
+//	defaults < backends < parameter files < .env files < .env.<profile> files < environment variables < flags < overrides
+//
+// The backends are the remote key-value stores added by AddBackend.
+// The flags are the command line flags: --KEY=value.
+// The overrides are the parameters set at runtime by Set.
 package engine
 
 import (
 	"fmt"
 	"github.com/ahmetson/datatype-lib/data_type/key_value"
-	"github.com/ahmetson/os-lib/env"
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Dev context's configuration engine on viper.Viper
@@ -19,29 +33,85 @@ type Dev struct {
 
 	// Passed as --secure command line arg.
 	// If it's passed, then authentication is switched off.
-	Secure       bool
-	HandleChange func(interface{}, error)
+	Secure         bool
+	HandleChange   func(interface{}, error)
+	SecretPatterns []string // name patterns of the parameters redacted in the export
+	KeySeparator   string   // splits the environment variables into the nested parameters, DefaultKeySeparator if empty
+
+	profile       string                  // configuration profile, for example dev or test
+	paramFiles    []string                // loaded parameter files in the loading order
+	files         []*paramFile            // parsed parameter files, restored if the reload fails
+	envFiles      []string                // loaded .env files in the loading order
+	registry      registry                // registered parameter schemas
+	watchers      watchers                // handlers of the parameter changes
+	fileKeys      map[string]string       // parameter file path by the lowercase key
+	processEnv    map[string]struct{}     // environment variables of the process
+	dotEnv        map[string]*envValue    // parameters loaded from the .env files
+	secretKey     []byte                  // key of the encrypted values in the .env files
+	secrets       *secretCache            // resolves the secret references
+	flags         map[string]string       // parameters bound from the command line flags by the lowercase key
+	mu            sync.RWMutex            // guards the defaults, backends, files, flags, overrides, names, secret key and secret provider
+	remote        map[string]*remoteValue // parameters loaded from the backends by the lowercase key
+	overrides     map[string]*override    // parameters set at runtime by the lowercase key
+	overridesPath string                  // file where the overrides are persisted
+	names         map[string]string       // original name by the lowercase key
 }
 
 // NewDev creates a global config for the entire application.
 //
 // Automatically reads the command line arguments.
 // Loads the environment variables.
-func NewDev() (*Dev, error) {
-	// First, we load the environment variables
-	err := env.LoadAnyEnv()
+// Binds the command line flags. The defaults are not set yet, so the unknown flags are not reported here:
+// call ValidateFlags or Validate after setting the defaults.
+// If the profile is set, then the profile .env files are loaded on top of the .env files.
+//
+// Optionally, the paramFiles are merged in the given order.
+// The latter file overwrites the parameters of the former file.
+// Supported formats are listed in ParamTypes.
+func NewDev(paramFiles ...string) (*Dev, error) {
+	config := newDev()
+
+	profile, err := ReadProfile()
 	if err != nil {
+		return nil, fmt.Errorf("ReadProfile: %w", err)
+	}
+	config.profile = profile
+
+	// First, we load the environment variables
+	if err := config.loadEnvFiles(); err != nil {
 		return nil, fmt.Errorf("loading environment variables: %w", err)
 	}
 
-	// replace the values with the ones we fetched from environment variables
-	config := Dev{
-		Viper:        viper.New(),
-		HandleChange: nil,
+	if err := config.loadFiles(paramFiles); err != nil {
+		return nil, fmt.Errorf("config.loadFiles: %w", err)
 	}
 	config.AutomaticEnv()
 
-	return &config, nil
+	if err := config.loadFlags(); err != nil {
+		return nil, fmt.Errorf("config.loadFlags: %w", err)
+	}
+
+	return config, nil
+}
+
+// newDev returns an engine without any loaded parameters.
+func newDev() *Dev {
+	return &Dev{
+		Viper:          viper.New(),
+		HandleChange:   nil,
+		SecretPatterns: slices.Clone(DefaultSecretPatterns),
+		KeySeparator:   DefaultKeySeparator,
+		paramFiles:     make([]string, 0),
+		envFiles:       make([]string, 0),
+		registry:       registry{schemas: make([]*Schema, 0)},
+		fileKeys:       make(map[string]string),
+		processEnv:     processEnvNames(),
+		dotEnv:         make(map[string]*envValue),
+		flags:          make(map[string]string),
+		remote:         make(map[string]*remoteValue),
+		overrides:      make(map[string]*override),
+		names:          make(map[string]string),
+	}
 }
 
 // YamlPathParam creates a file parameter.
@@ -52,22 +122,150 @@ func YamlPathParam(configPath string, configName string) key_value.KeyValue {
 		Set("type", "yaml")
 }
 
-// SetDefaults sets the default config parameters.
-func (config *Dev) SetDefaults(params key_value.KeyValue) {
+// SetDefaults sets the default config parameters at once.
+// The readers see either none or all the parameters.
+// The nil values and the parameters that are already set are skipped.
+//
+// Returns the sorted names of the parameters that are already set.
+func (config *Dev) SetDefaults(params key_value.KeyValue) []string {
+	names := make([]string, 0, len(params))
+	skipped := make([]string, 0)
 	for name, value := range params {
 		if value == nil {
 			continue
 		}
 		// already set, don't use the default
 		if config.IsSet(name) {
+			skipped = append(skipped, name)
 			continue
 		}
-		config.SetDefault(name, value)
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	slices.Sort(skipped)
+
+	previous := make([]interface{}, len(names))
+	for i, name := range names {
+		previous[i] = config.Get(name)
+	}
+
+	config.mu.Lock()
+	for _, name := range names {
+		config.names[strings.ToLower(name)] = name
+		config.Viper.SetDefault(name, params[name])
+	}
+	config.mu.Unlock()
+
+	for i, name := range names {
+		config.notify(name, previous[i])
 	}
+
+	return skipped
+}
+
+// SetDefault sets the default parameter.
+// It's used if the parameter is not set in any other source.
+func (config *Dev) SetDefault(name string, value interface{}) {
+	previous := config.Get(name)
+	config.mu.Lock()
+	config.names[strings.ToLower(name)] = name
+	config.Viper.SetDefault(name, value)
+	config.mu.Unlock()
+	config.notify(name, previous)
+}
+
+// Watch calls the handler when the parameter value is changed
+// by Set, Unset, SetDefault, the backends or the reloaded files.
+func (config *Dev) Watch(handler func(Change)) {
+	config.watchers.add(handler)
+}
+
+// notify the watchers if the parameter value is changed
+func (config *Dev) notify(name string, previous interface{}) {
+	source, _ := config.Source(name)
+	config.watchers.notify(Change{Name: name, Previous: previous, Value: config.Get(name), Source: source})
 }
 
 // Exist Checks whether the config variable exists or not
 // If the config exists or its default value exists, then returns true.
 func (config *Dev) Exist(name string) bool {
-	return config.Viper.Get(name) != nil
+	return config.raw(name) != nil
+}
+
+// IsSet returns true if the parameter is set in any source except the default values.
+func (config *Dev) IsSet(name string) bool {
+	key := strings.ToLower(name)
+
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	if _, ok := config.overrides[key]; ok {
+		return true
+	}
+	if _, ok := config.flags[key]; ok {
+		return true
+	}
+	if value := config.remoteParam(key); value != nil {
+		return true
+	}
+	return config.Viper.IsSet(name)
+}
+
+// raw returns the parameter from the source with the highest precedence.
+// The parameter is not interpolated.
+func (config *Dev) raw(name string) interface{} {
+	key := strings.ToLower(name)
+
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	if value, ok := config.overrides[key]; ok {
+		return value.value
+	}
+	if value, ok := config.flags[key]; ok {
+		return value
+	}
+	// the nested parameter, for example DB__HOST for db.host
+	if strings.Contains(name, ".") {
+		if envName, ok := config.envName(name); ok {
+			return os.Getenv(envName)
+		}
+	}
+	if value := config.remoteParam(key); value != nil {
+		return value.value
+	}
+	return config.Viper.Get(name)
+}
+
+//
+// The typed getters of viper.Viper are replaced
+// to read the parameters with the same precedence and interpolation as Get.
+//
+
+// GetString returns the parameter as a string
+func (config *Dev) GetString(name string) string {
+	return cast.ToString(config.Get(name))
+}
+
+// GetBool returns the parameter as a boolean
+func (config *Dev) GetBool(name string) bool {
+	return cast.ToBool(config.Get(name))
+}
+
+// GetUint64 returns the parameter as an uint64
+func (config *Dev) GetUint64(name string) uint64 {
+	return cast.ToUint64(config.Get(name))
+}
+
+// GetInt64 returns the parameter as an int64
+func (config *Dev) GetInt64(name string) int64 {
+	return cast.ToInt64(config.Get(name))
+}
+
+// GetFloat64 returns the parameter as a float64
+func (config *Dev) GetFloat64(name string) float64 {
+	return cast.ToFloat64(config.Get(name))
+}
+
+// GetDuration returns the parameter as time.Duration
+func (config *Dev) GetDuration(name string) time.Duration {
+	return cast.ToDuration(config.Get(name))
 }
@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/ahmetson/os-lib/path"
+	"io"
+	"os"
+	"strings"
+)
+
+//
+// Encrypted values in the .env files:
+//
+//	PRIVATE_KEY=enc:cGxhY2Vob2xkZXI...
+//
+// The values are encrypted by AES-256-GCM.
+// The key is 32 bytes encoded as the base64 string.
+//
+
+const (
+	// EncryptedPrefix marks the encrypted value
+	EncryptedPrefix = "enc:"
+	// EnvSecretKey is the environment variable with the base64 encoded key
+	EnvSecretKey = "CONFIG_SECRET_KEY"
+	// EnvSecretKeyFile is the environment variable with the path of the file that has the base64 encoded key.
+	// The file has a higher priority than EnvSecretKey.
+	EnvSecretKeyFile = "CONFIG_SECRET_KEY_FILE"
+	// SecretKeySize is the size of the AES-256 key in bytes
+	SecretKeySize = 32
+)
+
+// IsEncrypted returns true if the value has the EncryptedPrefix
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+// GenerateSecretKey returns a new random key encoded as the base64 string
+func GenerateSecretKey() (string, error) {
+	key := make([]byte, SecretKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// DecodeSecretKey returns the key from the base64 string
+func DecodeSecretKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("base64.DecodeString: %w", err)
+	}
+	if len(key) != SecretKeySize {
+		return nil, fmt.Errorf("key must be %d bytes, but it's %d bytes", SecretKeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// ReadSecretKey returns the key from the file set in EnvSecretKeyFile or from EnvSecretKey.
+// Returns nil if the key is not set.
+func ReadSecretKey() ([]byte, error) {
+	if filePath := os.Getenv(EnvSecretKeyFile); len(filePath) > 0 {
+		currentDir, err := path.CurrentDir()
+		if err != nil {
+			return nil, fmt.Errorf("path.CurrentDir: %w", err)
+		}
+		absPath := path.AbsDir(currentDir, filePath)
+
+		data, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile('%s'): %w", absPath, err)
+		}
+		key, err := DecodeSecretKey(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("DecodeSecretKey('%s'): %w", absPath, err)
+		}
+		return key, nil
+	}
+
+	if encoded := os.Getenv(EnvSecretKey); len(encoded) > 0 {
+		key, err := DecodeSecretKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("DecodeSecretKey(%s): %w", EnvSecretKey, err)
+		}
+		return key, nil
+	}
+
+	return nil, nil
+}
+
+// Encrypt the value with the key.
+// Returns the value with the EncryptedPrefix to store in the .env file.
+func Encrypt(value string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("newGCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt the value with the EncryptedPrefix.
+func Decrypt(value string, key []byte) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("value has no '%s' prefix", EncryptedPrefix)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("base64.DecodeString: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("newGCM: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("value is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("gcm.Open: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != SecretKeySize {
+		return nil, fmt.Errorf("key must be %d bytes, but it's %d bytes", SecretKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+	}
+
+	return gcm, nil
+}
+
+// decrypt the value loaded from the .env file.
+// The key is read when the first encrypted value is found.
+func (config *Dev) decrypt(name string, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	key, err := config.readSecretKey()
+	if err != nil {
+		return "", fmt.Errorf("config.readSecretKey: %w", err)
+	}
+	if key == nil {
+		return "", fmt.Errorf("'%s' is encrypted, set the key in %s or %s", name, EnvSecretKey, EnvSecretKeyFile)
+	}
+
+	decrypted, err := Decrypt(value, key)
+	if err != nil {
+		return "", fmt.Errorf("Decrypt('%s'): %w", name, err)
+	}
+
+	return decrypted, nil
+}
+
+// readSecretKey returns the key of the encrypted values, nil if the key is not set.
+// The key is kept in the engine once it's read, the reloads don't read it again.
+func (config *Dev) readSecretKey() ([]byte, error) {
+	config.mu.RLock()
+	key := config.secretKey
+	config.mu.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	key, err := ReadSecretKey()
+	if err != nil {
+		return nil, fmt.Errorf("ReadSecretKey: %w", err)
+	}
+	if key == nil {
+		return nil, nil
+	}
+
+	config.mu.Lock()
+	defer config.mu.Unlock()
+	// read by another goroutine meanwhile
+	if config.secretKey == nil {
+		config.secretKey = key
+	}
+	return config.secretKey, nil
+}
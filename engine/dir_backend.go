@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/ahmetson/os-lib/path"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DirBackend is the Backend that keeps the keys as the files in the directory.
+// The key is the file path relative to the directory with the slashes, and the value is the file content.
+//
+// It's the local stand-in of the remote key-value store to work offline.
+type DirBackend struct {
+	Dir      string
+	Interval time.Duration // how often the directory is checked for the changes
+}
+
+// NewDirBackend returns the backend on the directory.
+// The directory must exist.
+func NewDirBackend(dir string) (*DirBackend, error) {
+	exist, err := path.DirExist(dir)
+	if err != nil {
+		return nil, fmt.Errorf("path.DirExist('%s'): %w", dir, err)
+	}
+	if !exist {
+		return nil, fmt.Errorf("directory '%s' not found", dir)
+	}
+
+	return &DirBackend{Dir: dir, Interval: time.Millisecond * 200}, nil
+}
+
+// Get returns the content of the file.
+// Returns false if the file doesn't exist.
+func (backend *DirBackend) Get(key string) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(backend.Dir, filepath.FromSlash(key)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("os.ReadFile('%s'): %w", key, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// List returns the content of the files which relative path starts with the prefix.
+func (backend *DirBackend) List(prefix string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	err := filepath.WalkDir(backend.Dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(backend.Dir, filePath)
+		if err != nil {
+			return fmt.Errorf("filepath.Rel('%s'): %w", filePath, err)
+		}
+		key := filepath.ToSlash(relPath)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		value, _, err := backend.Get(key)
+		if err != nil {
+			return fmt.Errorf("backend.Get('%s'): %w", key, err)
+		}
+		values[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filepath.WalkDir('%s'): %w", backend.Dir, err)
+	}
+
+	return values, nil
+}
+
+// Watch checks the directory every Interval and calls the handler for the files
+// that are changed or deleted since the baseline.
+func (backend *DirBackend) Watch(ctx context.Context, prefix string, baseline map[string]string, handler func(BackendEvent)) error {
+	if handler == nil {
+		return fmt.Errorf("handler is nil")
+	}
+	if backend.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	values := make(map[string]string, len(baseline))
+	for key, value := range baseline {
+		values[key] = value
+	}
+
+	go func() {
+		ticker := time.NewTicker(backend.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := backend.List(prefix)
+			if err != nil {
+				// the directory might be edited at the moment, try again later
+				continue
+			}
+
+			for key, value := range current {
+				if previous, ok := values[key]; !ok || previous != value {
+					handler(BackendEvent{Key: key, Value: value})
+				}
+			}
+			for key := range values {
+				if _, ok := current[key]; !ok {
+					handler(BackendEvent{Key: key, Deleted: true})
+				}
+			}
+			values = current
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Interface of the configuration engine.
+// The engine keeps the parameters by the case-insensitive names.
+//
+// Dev is the engine on viper.Viper that reads the files, environment variables and flags.
+// Memory is the engine that keeps the parameters in the memory only.
+type Interface interface {
+	// Get returns the parameter or nil if it's not set
+	Get(name string) interface{}
+	// Set the parameter at runtime, it overwrites the parameter from any other source
+	Set(name string, value interface{})
+	// SetDefault sets the parameter that's used if the parameter is not set in any other source
+	SetDefault(name string, value interface{})
+	// Exist returns true if the parameter or its default value is set
+	Exist(name string) bool
+	// Keys returns the names of all parameters sorted alphabetically
+	Keys() []string
+	// Watch calls the handler when the parameter value changes
+	Watch(handler func(Change))
+}
+
+var (
+	_ Interface = (*Dev)(nil)
+	_ Interface = (*Memory)(nil)
+)
+
+// Defaulter is the engine that sets the default parameters at once
+type Defaulter interface {
+	// SetDefaults sets the default parameters, the readers see either none or all of them.
+	// Returns the sorted names of the parameters that are skipped, since they are set already.
+	SetDefaults(params key_value.KeyValue) []string
+}
+
+var (
+	_ Defaulter = (*Dev)(nil)
+	_ Defaulter = (*Memory)(nil)
+)
+
+// Unsetter is the engine that could remove the parameters set at runtime
+type Unsetter interface {
+	// Unset removes the parameter set at runtime, returns false if it was not set
+	Unset(name string) bool
+}
+
+// Persister is the engine that persists the parameters set at runtime
+type Persister interface {
+	LoadOverrides(filePath string) error
+	WriteOverrides() error
+	// Override returns the parameter set at runtime, false if it's not set
+	Override(name string) (interface{}, bool)
+}
+
+// Registry is the engine that validates the parameters by the schemas
+type Registry interface {
+	Register(schemas ...*Schema) error
+	Schemas() []*Schema
+	Validate() error
+}
+
+// Tracker is the engine that tracks the source of the parameters
+type Tracker interface {
+	Source(name string) (*Source, error)
+	Sources() map[string]*Source
+}
+
+// FlagValidator is the engine that binds the command line flags as the parameters
+type FlagValidator interface {
+	// ValidateFlags returns *UnknownFlagsError if any flag doesn't match a parameter
+	ValidateFlags() error
+}
+
+var _ FlagValidator = (*Dev)(nil)
+
+// Interpolator is the engine that expands the references to other parameters
+type Interpolator interface {
+	// Interpolate returns the parameter with the expanded references.
+	// Returns an error if the references can't be expanded.
+	Interpolate(name string) (interface{}, error)
+}
+
+var _ Interpolator = (*Dev)(nil)
+
+// SecretResolver is the engine that resolves the secret references of the parameters
+type SecretResolver interface {
+	SetSecretProvider(provider SecretProvider, ttl time.Duration) error
+	// Redact replaces the resolved secrets in the text
+	Redact(text string) string
+}
+
+var _ SecretResolver = (*Dev)(nil)
+
+// Exporter is the engine that exports the effective parameters
+type Exporter interface {
+	Export(format ExportFormat, secretPatterns ...string) ([]byte, error)
+}
+
+// Change of the parameter value.
+// The Value and Source are nil if the parameter was removed.
+type Change struct {
+	Name     string      `json:"name" yaml:"name"`
+	Previous interface{} `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Source   *Source     `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// watchers keep the handlers of the parameter changes
+type watchers struct {
+	sync.RWMutex
+	handlers []func(Change)
+}
+
+func (w *watchers) add(handler func(Change)) {
+	if handler == nil {
+		return
+	}
+
+	w.Lock()
+	w.handlers = append(w.handlers, handler)
+	w.Unlock()
+}
+
+// notify calls the handlers if the value is changed
+func (w *watchers) notify(change Change) {
+	if reflect.DeepEqual(change.Previous, change.Value) {
+		return
+	}
+
+	w.RLock()
+	handlers := slices.Clone(w.handlers)
+	w.RUnlock()
+
+	for _, handler := range handlers {
+		handler(change)
+	}
+}
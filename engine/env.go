@@ -0,0 +1,279 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/ahmetson/os-lib/arg"
+	"github.com/ahmetson/os-lib/path"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// loadedEnv keeps the environment variables set from the .env files by any engine in the process.
+// They are not counted as the process environment variables when another engine is created.
+var loadedEnv = struct {
+	sync.Mutex
+	values map[string]string
+}{values: make(map[string]string)}
+
+// envValue is the parameter loaded from the .env file
+type envValue struct {
+	value  string
+	source *Source
+}
+
+// loadEnvFiles loads the .env files passed as the command line arguments
+// into the environment variables.
+//
+// The process environment variables are not overwritten.
+// If the same parameter is defined in multiple files, the first file is used.
+//
+// If the profile is set, then the profile files (.env.<profile>) are loaded before the .env files.
+// Therefore, the profile files overwrite the .env files.
+// The missing profile files are skipped.
+func (config *Dev) loadEnvFiles() error {
+	currentDir, err := path.CurrentDir()
+	if err != nil {
+		return fmt.Errorf("path.CurrentDir: %w", err)
+	}
+
+	envPaths := arg.EnvPaths()
+	absPaths := make([]string, 0, len(envPaths)*2)
+	if len(config.profile) > 0 {
+		for _, envPath := range envPaths {
+			absPath := ProfilePath(path.AbsDir(currentDir, envPath), config.profile)
+			exist, err := path.FileExist(absPath)
+			if err != nil {
+				return fmt.Errorf("path.FileExist('%s'): %w", absPath, err)
+			}
+			if exist {
+				absPaths = append(absPaths, absPath)
+			}
+		}
+	}
+	for _, envPath := range envPaths {
+		absPaths = append(absPaths, path.AbsDir(currentDir, envPath))
+	}
+
+	dotEnv, err := config.readEnvFiles(absPaths)
+	if err != nil {
+		return fmt.Errorf("config.readEnvFiles: %w", err)
+	}
+	if err := config.applyEnv(dotEnv); err != nil {
+		return fmt.Errorf("config.applyEnv: %w", err)
+	}
+	config.envFiles = absPaths
+
+	return nil
+}
+
+// readEnvFiles returns the parameters of the .env files.
+// If the same parameter is defined in multiple files, the first file is used.
+// The process environment variables are skipped.
+func (config *Dev) readEnvFiles(filePaths []string) (map[string]*envValue, error) {
+	dotEnv := make(map[string]*envValue)
+	for _, filePath := range filePaths {
+		if err := config.readEnvFile(filePath, dotEnv); err != nil {
+			return nil, fmt.Errorf("readEnvFile('%s'): %w", filePath, err)
+		}
+	}
+
+	return dotEnv, nil
+}
+
+// readEnvFile adds the parameters of a single .env file into the dotEnv.
+// The encrypted values are decrypted, if decryption fails, then returns an error.
+func (config *Dev) readEnvFile(filePath string, dotEnv map[string]*envValue) error {
+	lines, err := parseEnvFile(filePath)
+	if err != nil {
+		return fmt.Errorf("parseEnvFile: %w", err)
+	}
+
+	for name, line := range lines {
+		if _, ok := dotEnv[name]; ok {
+			continue
+		}
+		if _, ok := config.processEnv[name]; ok {
+			continue
+		}
+
+		value, err := config.decrypt(name, line.value)
+		if err != nil {
+			return fmt.Errorf("config.decrypt: %w", err)
+		}
+
+		dotEnv[name] = &envValue{
+			value:  value,
+			source: &Source{Kind: EnvFileSource, Path: filePath, Line: line.number},
+		}
+	}
+
+	return nil
+}
+
+// applyEnv sets the parameters of the .env files as the environment variables.
+// The parameters removed from the .env files are removed from the environment variables.
+func (config *Dev) applyEnv(dotEnv map[string]*envValue) error {
+	loadedEnv.Lock()
+	defer loadedEnv.Unlock()
+
+	for name, loaded := range config.dotEnv {
+		if _, ok := dotEnv[name]; ok {
+			continue
+		}
+		// changed by someone else
+		if value, ok := os.LookupEnv(name); !ok || value != loaded.value {
+			continue
+		}
+		if err := os.Unsetenv(name); err != nil {
+			return fmt.Errorf("os.Unsetenv('%s'): %w", name, err)
+		}
+		delete(loadedEnv.values, name)
+	}
+
+	for name, loaded := range dotEnv {
+		if err := os.Setenv(name, loaded.value); err != nil {
+			return fmt.Errorf("os.Setenv('%s'): %w", name, err)
+		}
+		loadedEnv.values[name] = loaded.value
+	}
+	config.dotEnv = dotEnv
+
+	return nil
+}
+
+// EnvFiles returns the absolute paths of the loaded .env files in the loading order.
+func (config *Dev) EnvFiles() []string {
+	return slices.Clone(config.envFiles)
+}
+
+// envLine is the parameter defined in the .env file
+type envLine struct {
+	value  string
+	number int
+}
+
+// parseEnvFile returns the parameters of the .env file with their line numbers.
+// If the parameter is defined multiple times, the last line is returned.
+//
+// The references to other parameters are kept as they are.
+// They are expanded by the engine when the parameter is read, see Interpolate.
+func parseEnvFile(filePath string) (map[string]envLine, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+
+	lines := make(map[string]envLine)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, parseErr := parseEnvLine(line)
+		if parseErr != nil {
+			err = fmt.Errorf("line %d: %w", lineNumber, parseErr)
+			break
+		}
+		lines[name] = envLine{value: value, number: lineNumber}
+	}
+
+	if err == nil {
+		if err = scanner.Err(); err != nil {
+			err = fmt.Errorf("scanner.Scan: %w", err)
+		}
+	}
+	closeErr := f.Close()
+	if closeErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("%v: file.Close: %w", err, closeErr)
+		} else {
+			return nil, fmt.Errorf("file.Close: %w", closeErr)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// parseEnvLine returns the name and the value of the KEY=VALUE or KEY: VALUE line.
+//
+// The single-quoted values are kept as they are.
+// The double-quoted values have the \n, \r and \<char> escapes.
+// The unquoted values end at the comment that starts with the space and #.
+func parseEnvLine(line string) (string, string, error) {
+	line = strings.TrimPrefix(line, "export ")
+
+	end := strings.IndexAny(line, "=:")
+	if end <= 0 {
+		return "", "", fmt.Errorf("'%s' has no name and value separator", line)
+	}
+	name := strings.TrimSpace(line[:end])
+	value := strings.TrimSpace(line[end+1:])
+
+	if len(value) == 0 {
+		return name, value, nil
+	}
+
+	switch quote := value[0]; quote {
+	case '\'':
+		closing := strings.IndexByte(value[1:], quote)
+		if closing == -1 {
+			return "", "", fmt.Errorf("'%s' value has no closing quote", name)
+		}
+		return name, value[1 : closing+1], nil
+	case '"':
+		var unquoted strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch {
+			case value[i] == quote:
+				return name, unquoted.String(), nil
+			case value[i] == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					unquoted.WriteByte('\n')
+				case 'r':
+					unquoted.WriteByte('\r')
+				default:
+					unquoted.WriteByte(value[i])
+				}
+			default:
+				unquoted.WriteByte(value[i])
+			}
+		}
+		return "", "", fmt.Errorf("'%s' value has no closing quote", name)
+	}
+
+	if comment := strings.Index(value, " #"); comment != -1 {
+		value = strings.TrimSpace(value[:comment])
+	}
+
+	return name, value, nil
+}
+
+// processEnvNames returns the names of the environment variables set by the process.
+// The variables loaded from the .env files are excluded.
+func processEnvNames() map[string]struct{} {
+	loadedEnv.Lock()
+	defer loadedEnv.Unlock()
+
+	names := make(map[string]struct{})
+	for _, pair := range os.Environ() {
+		name, value, _ := strings.Cut(pair, "=")
+		if len(name) == 0 {
+			continue
+		}
+		if loaded, ok := loadedEnv.values[name]; ok && loaded == value {
+			continue
+		}
+		names[name] = struct{}{}
+	}
+
+	return names
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ExportFormat of the effective parameters
+type ExportFormat string
+
+const (
+	JsonFormat ExportFormat = "json"
+	YamlFormat ExportFormat = "yaml"
+	EnvFormat  ExportFormat = "env"
+)
+
+// ExportFormats lists the supported export formats
+var ExportFormats = []ExportFormat{JsonFormat, YamlFormat, EnvFormat}
+
+// Redacted replaces the value of the secret parameters in the export
+const Redacted = "[REDACTED]"
+
+// DefaultSecretPatterns are the name patterns of the secret parameters.
+// The patterns are case-insensitive, the syntax is the same as in filepath.Match.
+var DefaultSecretPatterns = []string{"*_KEY", "*_SECRET", "PRIVATE_*"}
+
+// ValidateExportFormat returns an error if the format is not supported.
+func ValidateExportFormat(format ExportFormat) error {
+	if slices.Contains(ExportFormats, format) {
+		return nil
+	}
+
+	return fmt.Errorf("'%s' is not valid export format, use one of %v", format, ExportFormats)
+}
+
+// ValidateSecretPatterns returns an error if any pattern is malformed.
+func ValidateSecretPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("filepath.Match('%s'): %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// IsSecret returns true if the parameter name matches any of the patterns.
+func IsSecret(name string, patterns []string) bool {
+	name = strings.ToUpper(name)
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(strings.ToUpper(pattern), name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Effective returns the value of every parameter in the engine by the parameter name.
+// The values of the parameters matching the secret patterns
+// or referring to the secrets are replaced by Redacted.
+//
+// If the secret patterns are not given, then SecretPatterns are used.
+func (config *Dev) Effective(secretPatterns ...string) map[string]interface{} {
+	if len(secretPatterns) == 0 {
+		secretPatterns = config.SecretPatterns
+	}
+	return effective(config, secretPatterns)
+}
+
+// Export the effective parameters in the given format.
+// The values of the parameters matching the secret patterns are redacted.
+// If the secret patterns are not given, then SecretPatterns are used.
+//
+// In the EnvFormat, the names are converted to the environment variable names.
+func (config *Dev) Export(format ExportFormat, secretPatterns ...string) ([]byte, error) {
+	if len(secretPatterns) == 0 {
+		secretPatterns = config.SecretPatterns
+	}
+	return export(config, format, secretPatterns)
+}
+
+// secretReferrer is the engine that has the secret references
+type secretReferrer interface {
+	secretRef(name string) bool
+}
+
+// effective returns the parameters of the engine with the redacted secrets
+func effective(configEngine Interface, secretPatterns []string) map[string]interface{} {
+	keys := configEngine.Keys()
+	params := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if IsSecret(key, secretPatterns) {
+			params[key] = Redacted
+			continue
+		}
+		if referrer, ok := configEngine.(secretReferrer); ok && referrer.secretRef(key) {
+			params[key] = Redacted
+			continue
+		}
+		params[key] = configEngine.Get(key)
+	}
+
+	return params
+}
+
+// export the effective parameters of the engine in the given format
+func export(configEngine Interface, format ExportFormat, secretPatterns []string) ([]byte, error) {
+	if err := ValidateExportFormat(format); err != nil {
+		return nil, fmt.Errorf("ValidateExportFormat: %w", err)
+	}
+	if err := ValidateSecretPatterns(secretPatterns); err != nil {
+		return nil, fmt.Errorf("ValidateSecretPatterns: %w", err)
+	}
+
+	params := effective(configEngine, secretPatterns)
+
+	switch format {
+	case JsonFormat:
+		data, err := json.MarshalIndent(params, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("json.MarshalIndent: %w", err)
+		}
+		return data, nil
+	case YamlFormat:
+		data, err := yaml.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("yaml.Marshal: %w", err)
+		}
+		return data, nil
+	default:
+		envParams := make(map[string]string, len(params))
+		for name, raw := range params {
+			value, err := envString(raw)
+			if err != nil {
+				return nil, fmt.Errorf("envString('%s'): %w", name, err)
+			}
+			envParams[strings.ToUpper(strings.ReplaceAll(name, ".", "_"))] = value
+		}
+		data, err := godotenv.Marshal(envParams)
+		if err != nil {
+			return nil, fmt.Errorf("godotenv.Marshal: %w", err)
+		}
+		return []byte(data + "\n"), nil
+	}
+}
+
+// envString converts the parameter to the .env value.
+// The lists and maps are converted to JSON.
+func envString(raw interface{}) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+	if value, err := ToString(raw); err == nil {
+		return value, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+	return string(data), nil
+}
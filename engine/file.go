@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ahmetson/os-lib/path"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ParamTypes lists the formats of the parameter files that engine could load.
+// The format is derived from the file extension.
+var ParamTypes = []string{"toml", "json", "ini", "yaml", "yml"}
+
+// ParamType returns the viper config type of the given file path.
+// If the file extension is not supported, then returns an error.
+func ParamType(filePath string) (string, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+	if !slices.Contains(ParamTypes, ext) {
+		return "", fmt.Errorf("'%s' extension is not supported, use one of %v", ext, ParamTypes)
+	}
+	if ext == "yml" {
+		return "yaml", nil
+	}
+
+	return ext, nil
+}
+
+// loadFiles merges the parameter files into the engine.
+// The files are merged in the given order, so the latter file overwrites the former.
+//
+// The relative paths are relative to the binary.
+func (config *Dev) loadFiles(filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+
+	currentDir, err := path.CurrentDir()
+	if err != nil {
+		return fmt.Errorf("path.CurrentDir: %w", err)
+	}
+
+	for _, filePath := range filePaths {
+		absPath := path.AbsDir(currentDir, filePath)
+		if err := config.loadFile(absPath); err != nil {
+			return fmt.Errorf("loadFile('%s'): %w", absPath, err)
+		}
+		config.paramFiles = append(config.paramFiles, absPath)
+	}
+
+	return nil
+}
+
+// paramFile is the parsed parameter file
+type paramFile struct {
+	path       string
+	configType string
+	data       []byte
+	keys       []string
+}
+
+// readParamFile reads and parses the parameter file.
+func readParamFile(filePath string) (*paramFile, error) {
+	configType, err := ParamType(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("ParamType: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	// track the file of each parameter
+	fileConfig := viper.New()
+	fileConfig.SetConfigType(configType)
+	if err := fileConfig.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("viper.ReadConfig: %w", err)
+	}
+
+	return &paramFile{path: filePath, configType: configType, data: data, keys: fileConfig.AllKeys()}, nil
+}
+
+// loadFile merges a single parameter file into the engine.
+func (config *Dev) loadFile(filePath string) error {
+	file, err := readParamFile(filePath)
+	if err != nil {
+		return fmt.Errorf("readParamFile: %w", err)
+	}
+
+	config.SetConfigType(file.configType)
+	if err := config.MergeConfig(bytes.NewReader(file.data)); err != nil {
+		return fmt.Errorf("viper.MergeConfig: %w", err)
+	}
+	for _, key := range file.keys {
+		config.fileKeys[key] = filePath
+	}
+	config.files = append(config.files, file)
+
+	return nil
+}
+
+// applyParamFiles replaces the parameters of the files in the engine.
+// The files are merged in the given order.
+//
+// The caller must hold the lock.
+func (config *Dev) applyParamFiles(files []*paramFile) error {
+	fileKeys := make(map[string]string)
+	for i, file := range files {
+		config.SetConfigType(file.configType)
+		if i == 0 {
+			if err := config.ReadConfig(bytes.NewReader(file.data)); err != nil {
+				return fmt.Errorf("viper.ReadConfig('%s'): %w", file.path, err)
+			}
+		} else if err := config.MergeConfig(bytes.NewReader(file.data)); err != nil {
+			return fmt.Errorf("viper.MergeConfig('%s'): %w", file.path, err)
+		}
+		for _, key := range file.keys {
+			fileKeys[key] = file.path
+		}
+	}
+	config.fileKeys = fileKeys
+	config.files = files
+
+	return nil
+}
+
+// ParamFiles returns the absolute paths of the loaded parameter files in the loading order.
+func (config *Dev) ParamFiles() []string {
+	return slices.Clone(config.paramFiles)
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/ahmetson/os-lib/arg"
+	"slices"
+	"strings"
+)
+
+const (
+	// SecureFlag switches off the authentication: --secure
+	SecureFlag = "secure"
+	// ConfigFlag is the path of the app configuration: --config=app.yml.
+	// It's the same flag as service.ConfigFlag.
+	ConfigFlag = "config"
+)
+
+// ReservedFlags are used by the config itself, they are not bound as the parameters
+var ReservedFlags = []string{SecureFlag, ProfileFlag, ConfigFlag}
+
+// UnknownFlagsError lists the flags that don't match any parameter
+type UnknownFlagsError struct {
+	Flags []string
+}
+
+func (e *UnknownFlagsError) Error() string {
+	flags := make([]string, len(e.Flags))
+	for i, flag := range e.Flags {
+		flags[i] = arg.NewFlag(flag)
+	}
+	return fmt.Sprintf("unknown flags: %s. The flag name must match a parameter set by default, "+
+		"parameter file, environment variable or schema", strings.Join(flags, ", "))
+}
+
+// loadFlags binds the command line flags into the parameters.
+// The flag without a value is set to "true": --debug is the same as --debug=true.
+//
+// The reserved flags are not bound.
+// The --secure flag sets the Secure field.
+func (config *Dev) loadFlags() error {
+	config.mu.Lock()
+	defer config.mu.Unlock()
+
+	for _, flag := range arg.Flags() {
+		name, value, hasValue := strings.Cut(flag, arg.Sep)
+		if len(name) == 0 {
+			continue
+		}
+		if !hasValue {
+			value = "true"
+		}
+
+		if name == SecureFlag {
+			secure, err := ToBool(value)
+			if err != nil {
+				return fmt.Errorf("--%s flag: %w", SecureFlag, err)
+			}
+			config.Secure = secure
+			continue
+		}
+		if slices.Contains(ReservedFlags, name) {
+			continue
+		}
+
+		key := strings.ToLower(name)
+		config.names[key] = name
+		config.flags[key] = value
+	}
+
+	return nil
+}
+
+// Flags returns the parameters bound from the command line flags by their names
+func (config *Dev) Flags() map[string]string {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+
+	flags := make(map[string]string, len(config.flags))
+	for key, value := range config.flags {
+		flags[config.names[key]] = value
+	}
+
+	return flags
+}
+
+// ValidateFlags returns *UnknownFlagsError if any flag doesn't match the parameter
+// from the other sources or the registered schema.
+//
+// Call it after the default parameters are set.
+func (config *Dev) ValidateFlags() error {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+
+	unknown := make([]string, 0)
+	for key := range config.flags {
+		if config.Viper.Get(key) != nil {
+			continue
+		}
+		if _, ok := config.overrides[key]; ok {
+			continue
+		}
+		if config.registry.registered(key) {
+			continue
+		}
+		unknown = append(unknown, config.names[key])
+	}
+
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return &UnknownFlagsError{Flags: unknown}
+	}
+
+	return nil
+}
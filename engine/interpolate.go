@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//
+// Interpolation of the parameters.
+//
+// The string parameter could refer to other parameters:
+//
+//	DB_URL=postgres://${DB_HOST}:${DB_PORT:-5432}/app
+//
+// The ${KEY} is replaced by the KEY parameter.
+// The ${KEY:-fallback} is replaced by the fallback if the KEY is missing or empty.
+// The $$ is replaced by a literal $.
+//
+// The secret reference secret://path is resolved by the SecretProvider after the interpolation.
+//
+
+// Get returns the parameter with the interpolated references to other parameters.
+// If the interpolation fails, then the raw parameter is returned.
+// If the secret reference can't be resolved, then nil is returned instead of the reference.
+//
+// Use Interpolate for the strict reads that fail on the missing references, cycles or invalid syntax.
+func (config *Dev) Get(name string) interface{} {
+	value, err := config.Interpolate(name)
+	if err != nil {
+		var secretErr *SecretError
+		if errors.As(err, &secretErr) {
+			return nil
+		}
+		return config.raw(name)
+	}
+
+	return value
+}
+
+// Interpolate returns the parameter with the interpolated references to other parameters.
+// Returns an error if the referred parameter is missing or the references make a cycle.
+func (config *Dev) Interpolate(name string) (interface{}, error) {
+	return config.interpolate(name, []string{})
+}
+
+// interpolate the parameter.
+// The stack is the list of the parameters that are being interpolated to detect the cycles.
+func (config *Dev) interpolate(name string, stack []string) (interface{}, error) {
+	key := strings.ToLower(name)
+	for i := range stack {
+		if stack[i] == key {
+			return nil, fmt.Errorf("cycle: %s -> %s", strings.Join(stack[i:], " -> "), key)
+		}
+	}
+
+	raw := config.raw(name)
+	str, ok := raw.(string)
+	if !ok {
+		return raw, nil
+	}
+
+	expanded, err := config.expand(str, append(stack, key))
+	if err != nil {
+		return nil, err
+	}
+	if IsSecretRef(expanded) {
+		return config.secret(name, expanded)
+	}
+
+	return expanded, nil
+}
+
+// expand replaces the references in the str.
+func (config *Dev) expand(str string, stack []string) (string, error) {
+	if !strings.Contains(str, "$") {
+		return str, nil
+	}
+
+	var expanded strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] != '$' || i+1 == len(str) {
+			expanded.WriteByte(str[i])
+			continue
+		}
+
+		// escaped $
+		if str[i+1] == '$' {
+			expanded.WriteByte('$')
+			i++
+			continue
+		}
+
+		if str[i+1] != '{' {
+			expanded.WriteByte(str[i])
+			continue
+		}
+
+		end := closingBrace(str, i+2)
+		if end == -1 {
+			return "", fmt.Errorf("'%s' has no closing brace at %d", str, i+1)
+		}
+
+		value, err := config.reference(str[i+2:end], stack)
+		if err != nil {
+			return "", err
+		}
+		expanded.WriteString(value)
+		i = end
+	}
+
+	return expanded.String(), nil
+}
+
+// reference returns the value of ${KEY} or ${KEY:-fallback} reference.
+// The fallback is expanded too.
+func (config *Dev) reference(ref string, stack []string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(ref, ":-")
+	if len(name) == 0 {
+		return "", fmt.Errorf("empty reference in '%s'", stack[len(stack)-1])
+	}
+
+	if config.raw(name) == nil {
+		if hasFallback {
+			return config.expand(fallback, stack)
+		}
+		return "", fmt.Errorf("%w: '%s' referred by '%s'", ErrNotFound, name, stack[len(stack)-1])
+	}
+
+	raw, err := config.interpolate(name, stack)
+	if err != nil {
+		return "", err
+	}
+	value, err := ToString(raw)
+	if err != nil {
+		return "", fmt.Errorf("ToString('%s'): %w", name, err)
+	}
+	if len(value) == 0 && hasFallback {
+		return config.expand(fallback, stack)
+	}
+
+	return value, nil
+}
+
+// closingBrace returns the position of the brace that closes the reference.
+// The nested references are skipped.
+// Returns -1 if the reference is not closed.
+func closingBrace(str string, start int) int {
+	depth := 0
+	for i := start; i < len(str); i++ {
+		switch {
+		case str[i] == '$' && i+1 < len(str) && str[i+1] == '{':
+			depth++
+			i++
+		case str[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
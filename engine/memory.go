@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Memory is the configuration engine that keeps the parameters in the memory only.
+// It doesn't read the files, environment variables or flags,
+// therefore the engines don't share any state of the process.
+//
+// The parameters set at runtime overwrite the default parameters.
+type Memory struct {
+	SecretPatterns []string // name patterns of the parameters redacted in the export
+	KeySeparator   string   // splits the parameter names into the nested parameters, DefaultKeySeparator if empty
+
+	mu       sync.RWMutex
+	defaults map[string]interface{} // default parameters by the lowercase key
+	values   map[string]interface{} // parameters set at runtime by the lowercase key
+	names    map[string]string      // original name by the lowercase key
+	registry registry               // registered parameter schemas
+	watchers watchers               // handlers of the parameter changes
+}
+
+// NewMemory returns an empty in-memory engine
+func NewMemory() *Memory {
+	return &Memory{
+		SecretPatterns: slices.Clone(DefaultSecretPatterns),
+		KeySeparator:   DefaultKeySeparator,
+		defaults:       make(map[string]interface{}),
+		values:         make(map[string]interface{}),
+		names:          make(map[string]string),
+		registry:       registry{schemas: make([]*Schema, 0)},
+	}
+}
+
+// get returns the parameter without locking
+func (m *Memory) get(key string) interface{} {
+	if value, ok := m.values[key]; ok {
+		return value
+	}
+	return m.defaults[key]
+}
+
+// Get returns the parameter or nil if it's not set
+func (m *Memory) Get(name string) interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.get(strings.ToLower(name))
+}
+
+// Set the parameter at runtime.
+// It overwrites the default parameter.
+func (m *Memory) Set(name string, value interface{}) {
+	key := strings.ToLower(name)
+
+	m.mu.Lock()
+	previous := m.get(key)
+	m.names[key] = name
+	m.values[key] = value
+	m.mu.Unlock()
+
+	m.watchers.notify(Change{Name: name, Previous: previous, Value: value, Source: &Source{Kind: SetSource}})
+}
+
+// SetDefault sets the default parameter.
+// It's used if the parameter is not set at runtime.
+func (m *Memory) SetDefault(name string, value interface{}) {
+	key := strings.ToLower(name)
+
+	m.mu.Lock()
+	previous := m.get(key)
+	m.names[key] = name
+	m.defaults[key] = value
+	current := m.get(key)
+	m.mu.Unlock()
+
+	m.notify(name, previous, current)
+}
+
+// SetDefaults sets the default parameters at once.
+// The readers see either none or all the parameters.
+// The nil values are skipped.
+//
+// The default is kept under the parameter set at runtime, so no parameter is skipped.
+func (m *Memory) SetDefaults(params key_value.KeyValue) []string {
+	names := make([]string, 0, len(params))
+	for name, value := range params {
+		if value != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	previous := make([]interface{}, len(names))
+	current := make([]interface{}, len(names))
+
+	m.mu.Lock()
+	for i, name := range names {
+		key := strings.ToLower(name)
+		previous[i] = m.get(key)
+		m.names[key] = name
+		m.defaults[key] = params[name]
+		current[i] = m.get(key)
+	}
+	m.mu.Unlock()
+
+	for i, name := range names {
+		m.notify(name, previous[i], current[i])
+	}
+
+	return []string{}
+}
+
+// Unset removes the parameter set at runtime.
+// Then the default parameter is used.
+//
+// Returns false if the parameter was not set at runtime.
+func (m *Memory) Unset(name string) bool {
+	key := strings.ToLower(name)
+
+	m.mu.Lock()
+	previous, ok := m.values[key]
+	if !ok {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.values, key)
+	current := m.get(key)
+	m.mu.Unlock()
+
+	m.notify(name, previous, current)
+	return true
+}
+
+// notify the watchers if the parameter value is changed
+func (m *Memory) notify(name string, previous interface{}, current interface{}) {
+	source, _ := m.Source(name)
+	m.watchers.notify(Change{Name: name, Previous: previous, Value: current, Source: source})
+}
+
+// Exist returns true if the parameter or its default value is set
+func (m *Memory) Exist(name string) bool {
+	return m.Get(name) != nil
+}
+
+// Keys returns the names of all parameters sorted alphabetically
+func (m *Memory) Keys() []string {
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.names))
+	for key, name := range m.names {
+		if m.get(key) != nil {
+			keys = append(keys, name)
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.Sort(keys)
+	return keys
+}
+
+// Watch calls the handler when the parameter value is changed by Set, Unset or SetDefault.
+func (m *Memory) Watch(handler func(Change)) {
+	m.watchers.add(handler)
+}
+
+// Source returns where the parameter value came from.
+// Returns ErrNotFound if the parameter is not set.
+func (m *Memory) Source(name string) (*Source, error) {
+	key := strings.ToLower(name)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.values[key]; ok {
+		return &Source{Kind: SetSource}, nil
+	}
+	if m.defaults[key] != nil {
+		return &Source{Kind: DefaultSource}, nil
+	}
+
+	return nil, fmt.Errorf("%w: '%s'", ErrNotFound, name)
+}
+
+// Sources returns the source of every parameter in the engine.
+func (m *Memory) Sources() map[string]*Source {
+	keys := m.Keys()
+	sources := make(map[string]*Source, len(keys))
+	for _, key := range keys {
+		source, err := m.Source(key)
+		if err != nil {
+			continue
+		}
+		sources[key] = source
+	}
+
+	return sources
+}
+
+// Register the parameter schemas.
+// The schema with the same key replaces the former one.
+// The default values of the schemas are set as the default parameters by SetDefaults.
+func (m *Memory) Register(schemas ...*Schema) error {
+	return m.registry.register(m, schemas...)
+}
+
+// Schemas returns the registered parameter schemas
+func (m *Memory) Schemas() []*Schema {
+	return m.registry.list()
+}
+
+// Validate all registered parameters.
+// Returns *ValidationError that lists every problem.
+func (m *Memory) Validate() error {
+	problems := m.registry.problems(func(name string) (interface{}, error) {
+		return m.Get(name), nil
+	})
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// Export the effective parameters in the given format.
+// The values of the parameters matching the secret patterns are redacted.
+// If the secret patterns are not given, then SecretPatterns are used.
+func (m *Memory) Export(format ExportFormat, secretPatterns ...string) ([]byte, error) {
+	if len(secretPatterns) == 0 {
+		secretPatterns = m.SecretPatterns
+	}
+	return export(m, format, secretPatterns)
+}
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/ahmetson/os-lib/path"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// override is the parameter set at runtime
+type override struct {
+	value  interface{}
+	source *Source
+}
+
+// Set the parameter at runtime.
+// It overwrites the parameter from any other source.
+//
+// The parameter is not persisted until WriteOverrides is called.
+func (config *Dev) Set(name string, value interface{}) {
+	previous := config.Get(name)
+	key := strings.ToLower(name)
+	config.mu.Lock()
+	config.names[key] = name
+	config.overrides[key] = &override{value: value, source: &Source{Kind: SetSource}}
+	config.mu.Unlock()
+	config.notify(name, previous)
+}
+
+// Unset removes the parameter set at runtime.
+// Then the parameter from other sources is used.
+//
+// Returns false if the parameter was not set at runtime.
+func (config *Dev) Unset(name string) bool {
+	key := strings.ToLower(name)
+	previous := config.Get(name)
+
+	config.mu.Lock()
+	_, ok := config.overrides[key]
+	delete(config.overrides, key)
+	config.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	config.notify(name, previous)
+	return true
+}
+
+// Overrides returns the parameters set at runtime by their names
+func (config *Dev) Overrides() map[string]interface{} {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+
+	return config.overridesByName()
+}
+
+// Override returns the parameter set at runtime.
+// Returns false if the parameter is not set at runtime.
+func (config *Dev) Override(name string) (interface{}, bool) {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+
+	value, ok := config.overrides[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return value.value, true
+}
+
+// overridesByName returns the parameters set at runtime by their names.
+// The caller must hold the lock.
+func (config *Dev) overridesByName() map[string]interface{} {
+	overrides := make(map[string]interface{}, len(config.overrides))
+	for key, value := range config.overrides {
+		overrides[config.names[key]] = value.value
+	}
+
+	return overrides
+}
+
+// LoadOverrides sets the parameters persisted in the yaml file.
+// The file is used by WriteOverrides later.
+//
+// If the file doesn't exist, then nothing is loaded.
+func (config *Dev) LoadOverrides(filePath string) error {
+	config.overridesPath = filePath
+
+	exist, err := path.FileExist(filePath)
+	if err != nil {
+		return fmt.Errorf("path.FileExist('%s'): %w", filePath, err)
+	}
+	if !exist {
+		return nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile('%s'): %w", filePath, err)
+	}
+
+	overrides := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	config.mu.Lock()
+	defer config.mu.Unlock()
+	for name, value := range overrides {
+		if value == nil {
+			continue
+		}
+		key := strings.ToLower(name)
+		config.names[key] = name
+		config.overrides[key] = &override{
+			value:  value,
+			source: &Source{Kind: SetSource, Path: filePath},
+		}
+	}
+
+	return nil
+}
+
+// WriteOverrides persists the parameters set at runtime into the file passed to LoadOverrides.
+// If there are no parameters set at runtime, then the file is removed.
+func (config *Dev) WriteOverrides() error {
+	if len(config.overridesPath) == 0 {
+		return fmt.Errorf("overrides file is not loaded, call LoadOverrides")
+	}
+
+	// the overrides are not changed while they are persisted
+	config.mu.Lock()
+	defer config.mu.Unlock()
+
+	if len(config.overrides) == 0 {
+		if err := os.Remove(config.overridesPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("os.Remove('%s'): %w", config.overridesPath, err)
+		}
+		return nil
+	}
+
+	data, err := yaml.Marshal(config.overridesByName())
+	if err != nil {
+		return fmt.Errorf("yaml.Marshal: %w", err)
+	}
+
+	if err := writeFile(config.overridesPath, data); err != nil {
+		return fmt.Errorf("writeFile('%s'): %w", config.overridesPath, err)
+	}
+
+	for _, value := range config.overrides {
+		value.source.Path = config.overridesPath
+	}
+
+	return nil
+}
+
+// writeFile replaces the file atomically, the file is not corrupted if the process is stopped while writing.
+// The data is written into the temporary file in the same directory, flushed to the disk,
+// then the temporary file is renamed to the file.
+//
+// The file is accessible by the owner only.
+func writeFile(filePath string, data []byte) error {
+	dir, fileName := filepath.Split(filePath)
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	f, err := os.CreateTemp(dir, "."+fileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	tempPath := f.Name()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if closeErr != nil {
+		_ = os.Remove(tempPath)
+		if err != nil {
+			return fmt.Errorf("%v: file.Close: %w", err, closeErr)
+		} else {
+			return fmt.Errorf("file.Close: %w", closeErr)
+		}
+	} else if err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("file.Write: %w", err)
+	}
+
+	if err := os.Rename(tempPath, filePath); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	// the rename is durable after the directory is synced.
+	// windows doesn't support syncing the directories.
+	if runtime.GOOS != "windows" {
+		if err := syncDir(dir); err != nil {
+			return fmt.Errorf("syncDir('%s'): %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// syncDir flushes the directory entries to the disk
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+
+	err = f.Sync()
+	closeErr := f.Close()
+	if closeErr != nil {
+		if err != nil {
+			return fmt.Errorf("%v: file.Close: %w", err, closeErr)
+		} else {
+			return fmt.Errorf("file.Close: %w", closeErr)
+		}
+	} else if err != nil {
+		return fmt.Errorf("file.Sync: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cast"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned if the parameter is not set in any source
+	ErrNotFound = errors.New("parameter not found")
+	// ErrConversion is returned if the parameter can not be converted to the requested type
+	ErrConversion = errors.New("parameter conversion failed")
+	// ErrInterpolation is returned if the references of the parameter can not be expanded
+	ErrInterpolation = errors.New("parameter interpolation failed")
+)
+
+//
+// Conversion of the raw parameters to the typed values.
+//
+// The environment variables are always strings,
+// while the parameter files and default values could keep any type.
+//
+
+// ToString converts the raw parameter to a string
+func ToString(raw interface{}) (string, error) {
+	return cast.ToStringE(raw)
+}
+
+// ToUint64 converts the raw parameter to an uint64
+func ToUint64(raw interface{}) (uint64, error) {
+	return cast.ToUint64E(raw)
+}
+
+// ToBool converts the raw parameter to a boolean
+func ToBool(raw interface{}) (bool, error) {
+	return cast.ToBoolE(raw)
+}
+
+// ToFloat64 converts the raw parameter to a float64
+func ToFloat64(raw interface{}) (float64, error) {
+	return cast.ToFloat64E(raw)
+}
+
+// ToInt64 converts the raw parameter to an int64
+func ToInt64(raw interface{}) (int64, error) {
+	return cast.ToInt64E(raw)
+}
+
+// ToDuration converts the raw parameter to time.Duration.
+// The string parameter is parsed by time.ParseDuration, for example "1m30s".
+// The number parameter is counted as nanoseconds.
+func ToDuration(raw interface{}) (time.Duration, error) {
+	return cast.ToDurationE(raw)
+}
+
+// ToStringSlice converts the raw parameter to the list of strings.
+//
+// The string parameter could be a JSON list or the comma separated values.
+// For example, `["a", "b"]` or `a, b`.
+func ToStringSlice(raw interface{}) ([]string, error) {
+	str, ok := raw.(string)
+	if !ok {
+		return cast.ToStringSliceE(raw)
+	}
+
+	str = strings.TrimSpace(str)
+	if len(str) == 0 {
+		return []string{}, nil
+	}
+
+	if strings.HasPrefix(str, "[") {
+		var list []interface{}
+		if err := json.Unmarshal([]byte(str), &list); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal('%s'): %w", str, err)
+		}
+		return cast.ToStringSliceE(list)
+	}
+
+	parts := strings.Split(str, ",")
+	list := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		list = append(list, part)
+	}
+
+	return list, nil
+}
+
+// ToStringMap converts the raw parameter to the map of strings.
+//
+// The string parameter could be a JSON object or the comma separated key=value pairs.
+// For example, `{"a": "1", "b": "2"}` or `a=1, b=2`.
+func ToStringMap(raw interface{}) (map[string]string, error) {
+	str, ok := raw.(string)
+	if !ok {
+		return cast.ToStringMapStringE(raw)
+	}
+
+	str = strings.TrimSpace(str)
+	if len(str) == 0 {
+		return map[string]string{}, nil
+	}
+
+	if strings.HasPrefix(str, "{") {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(str), &object); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal('%s'): %w", str, err)
+		}
+		return cast.ToStringMapStringE(object)
+	}
+
+	parts := strings.Split(str, ",")
+	object := make(map[string]string, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("'%s' is not a key=value pair", part)
+		}
+		object[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return object, nil
+}
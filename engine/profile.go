@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/ahmetson/os-lib/arg"
+	"os"
+	"strings"
+)
+
+const (
+	// ProfileFlag is the command line flag of the configuration profile: --profile=dev
+	ProfileFlag = "profile"
+	// EnvProfile is the environment variable of the configuration profile.
+	// The flag has a higher priority.
+	EnvProfile = "CONFIG_PROFILE"
+)
+
+// ReadProfile returns the configuration profile passed by the ProfileFlag or EnvProfile.
+// Returns an empty string if the profile is not set.
+func ReadProfile() (string, error) {
+	profile := ""
+	if arg.FlagExist(ProfileFlag) {
+		profile = arg.FlagValue(ProfileFlag)
+		if len(profile) == 0 {
+			return "", fmt.Errorf("--%s flag has no value", ProfileFlag)
+		}
+	} else {
+		profile = os.Getenv(EnvProfile)
+	}
+
+	if err := ValidateProfile(profile); err != nil {
+		return "", fmt.Errorf("ValidateProfile('%s'): %w", profile, err)
+	}
+
+	return profile, nil
+}
+
+// ValidateProfile returns an error if the profile can not be a part of the file name.
+// The empty profile is valid, it means no profile.
+func ValidateProfile(profile string) error {
+	if strings.ContainsAny(profile, `/\.`) || strings.TrimSpace(profile) != profile {
+		return fmt.Errorf("profile must not contain dots, slashes and spaces around")
+	}
+
+	return nil
+}
+
+// ProfilePath returns the path of the profile file.
+// The profile is appended to the .env files: .env.dev.
+// For other files, the profile is inserted before the extension: app.dev.yml.
+//
+// Returns the filePath as is, if the profile is empty.
+func ProfilePath(filePath string, profile string) string {
+	if len(profile) == 0 {
+		return filePath
+	}
+	if strings.HasSuffix(filePath, ".env") {
+		return filePath + "." + profile
+	}
+
+	i := strings.LastIndex(filePath, ".")
+	if i == -1 || strings.ContainsAny(filePath[i:], `/\`) {
+		return filePath + "." + profile
+	}
+	return filePath[:i] + "." + profile + filePath[i:]
+}
+
+// Profile returns the configuration profile of the engine.
+// Returns an empty string if the profile is not set.
+func (config *Dev) Profile() string {
+	return config.profile
+}
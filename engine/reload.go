@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// values returns the parameters and their names by the lowercase key
+func (config *Dev) values() (map[string]interface{}, map[string]string) {
+	keys := config.Keys()
+	values := make(map[string]interface{}, len(keys))
+	names := make(map[string]string, len(keys))
+	for _, name := range keys {
+		key := strings.ToLower(name)
+		values[key] = config.Get(name)
+		names[key] = name
+	}
+
+	return values, names
+}
+
+// Reload reads the .env and parameter files again.
+// The changed parameters are passed to the Watch handlers.
+// To reload the files on every change, use watch.WatchFiles.
+//
+// The process environment variables are not changed.
+// If any file can not be read or applied, then the engine is not changed.
+func (config *Dev) Reload() error {
+	previous, names := config.values()
+
+	dotEnv, err := config.readEnvFiles(config.envFiles)
+	if err != nil {
+		return fmt.Errorf("config.readEnvFiles: %w", err)
+	}
+	files := make([]*paramFile, len(config.paramFiles))
+	for i, filePath := range config.paramFiles {
+		files[i], err = readParamFile(filePath)
+		if err != nil {
+			return fmt.Errorf("readParamFile('%s'): %w", filePath, err)
+		}
+	}
+
+	config.mu.Lock()
+	err = config.apply(dotEnv, files)
+	config.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("config.apply: %w", err)
+	}
+
+	current, currentNames := config.values()
+	for key, name := range currentNames {
+		names[key] = name
+	}
+
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if reflect.DeepEqual(previous[key], current[key]) {
+			continue
+		}
+		name := names[key]
+		source, _ := config.Source(name)
+		config.watchers.notify(Change{Name: name, Previous: previous[key], Value: current[key], Source: source})
+	}
+
+	return nil
+}
+
+// apply sets the parameters of the .env and parameter files together.
+// If any of them fails, then the former parameters of both are restored.
+//
+// The caller must hold the lock.
+func (config *Dev) apply(dotEnv map[string]*envValue, files []*paramFile) error {
+	previousEnv, previousFiles := config.dotEnv, config.files
+
+	err := config.applyEnv(dotEnv)
+	if err == nil {
+		err = config.applyParamFiles(files)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// the failed applyEnv could set some variables, they are removed as the loaded ones
+	config.dotEnv = dotEnv
+	if restoreErr := config.applyEnv(previousEnv); restoreErr != nil {
+		return fmt.Errorf("%v: restore the .env files: %w", err, restoreErr)
+	}
+	if restoreErr := config.applyParamFiles(previousFiles); restoreErr != nil {
+		return fmt.Errorf("%v: restore the parameter files: %w", err, restoreErr)
+	}
+
+	return err
+}
@@ -0,0 +1,288 @@
+package engine
+
+import (
+	"fmt"
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	"github.com/ahmetson/os-lib/arg"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Kind of the parameter defined in the Schema
+type Kind string
+
+const (
+	StringKind      Kind = "string"
+	BoolKind        Kind = "bool"
+	Uint64Kind      Kind = "uint64"
+	Int64Kind       Kind = "int64"
+	Float64Kind     Kind = "float64"
+	DurationKind    Kind = "duration"
+	StringSliceKind Kind = "string_slice"
+	StringMapKind   Kind = "string_map"
+)
+
+// Kinds lists the supported parameter kinds
+var Kinds = []Kind{StringKind, BoolKind, Uint64Kind, Int64Kind, Float64Kind, DurationKind, StringSliceKind, StringMapKind}
+
+// Schema describes the parameter that the service expects.
+//
+// Fields
+//   - Key is the parameter name
+//   - Type of the parameter
+//   - Required parameter must be set in any source, or have a Default value
+//   - Default value of the parameter
+//   - Min and Max are the allowed range for the numbers and durations in seconds
+//   - Enum is the list of allowed values. For the string slice, each element must be allowed
+//   - Description of the parameter for the users
+type Schema struct {
+	Key         string      `json:"key" yaml:"key"`
+	Type        Kind        `json:"type" yaml:"type"`
+	Required    bool        `json:"required,omitempty" yaml:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	Min         *float64    `json:"min,omitempty" yaml:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty" yaml:"max,omitempty"`
+	Enum        []string    `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Problem of the parameter found during the validation
+type Problem struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError is the aggregated report of all problems found during the validation
+type ValidationError struct {
+	Problems []*Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		lines[i] = fmt.Sprintf("  - %s: %s", problem.Key, problem.Reason)
+	}
+	return fmt.Sprintf("%d invalid parameters:\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// ValidateKind returns an error if the kind is not supported.
+func ValidateKind(kind Kind) error {
+	if slices.Contains(Kinds, kind) {
+		return nil
+	}
+
+	return fmt.Errorf("'%s' is not valid parameter kind, use one of %v", kind, Kinds)
+}
+
+// IsValid returns an error if the schema itself is not valid.
+func (schema *Schema) IsValid() error {
+	if schema == nil {
+		return fmt.Errorf("schema is nil")
+	}
+	if len(schema.Key) == 0 {
+		return fmt.Errorf("key is empty")
+	}
+	if err := ValidateKind(schema.Type); err != nil {
+		return fmt.Errorf("ValidateKind: %w", err)
+	}
+	if schema.Min != nil && schema.Max != nil && *schema.Min > *schema.Max {
+		return fmt.Errorf("min %v is greater than max %v", *schema.Min, *schema.Max)
+	}
+	if schema.Default != nil {
+		if reason := schema.check(schema.Default); len(reason) > 0 {
+			return fmt.Errorf("default value: %s", reason)
+		}
+	}
+
+	return nil
+}
+
+// check returns the reason why the value doesn't match the schema.
+// Returns an empty string if the value is valid.
+func (schema *Schema) check(raw interface{}) string {
+	var number *float64
+	var values []string
+
+	switch schema.Type {
+	case StringKind:
+		value, err := ToString(raw)
+		if err != nil {
+			return fmt.Sprintf("not a string: %v", err)
+		}
+		values = []string{value}
+	case BoolKind:
+		if _, err := ToBool(raw); err != nil {
+			return fmt.Sprintf("not a bool: %v", err)
+		}
+	case Uint64Kind:
+		value, err := ToUint64(raw)
+		if err != nil {
+			return fmt.Sprintf("not an uint64: %v", err)
+		}
+		converted := float64(value)
+		number = &converted
+	case Int64Kind:
+		value, err := ToInt64(raw)
+		if err != nil {
+			return fmt.Sprintf("not an int64: %v", err)
+		}
+		converted := float64(value)
+		number = &converted
+	case Float64Kind:
+		value, err := ToFloat64(raw)
+		if err != nil {
+			return fmt.Sprintf("not a float64: %v", err)
+		}
+		number = &value
+	case DurationKind:
+		value, err := ToDuration(raw)
+		if err != nil {
+			return fmt.Sprintf("not a duration: %v", err)
+		}
+		converted := value.Seconds()
+		number = &converted
+	case StringSliceKind:
+		value, err := ToStringSlice(raw)
+		if err != nil {
+			return fmt.Sprintf("not a string slice: %v", err)
+		}
+		values = value
+	case StringMapKind:
+		if _, err := ToStringMap(raw); err != nil {
+			return fmt.Sprintf("not a string map: %v", err)
+		}
+	}
+
+	if number != nil {
+		if schema.Min != nil && *number < *schema.Min {
+			return fmt.Sprintf("%v is less than min %v", *number, *schema.Min)
+		}
+		if schema.Max != nil && *number > *schema.Max {
+			return fmt.Sprintf("%v is greater than max %v", *number, *schema.Max)
+		}
+		value, _ := ToString(raw)
+		values = []string{value}
+	}
+
+	if len(schema.Enum) > 0 {
+		for _, value := range values {
+			if !slices.Contains(schema.Enum, value) {
+				return fmt.Sprintf("'%s' is not one of %v", value, schema.Enum)
+			}
+		}
+	}
+
+	return ""
+}
+
+// registry of the parameter schemas, each engine has its own registry
+type registry struct {
+	sync.RWMutex
+	schemas []*Schema
+}
+
+// register the schemas in the registry.
+// The schema with the same key replaces the former one.
+// The default values of the schemas are set in the engine at once, see Defaulter.
+func (r *registry) register(configEngine Defaulter, schemas ...*Schema) error {
+	defaults := key_value.New()
+	for i, schema := range schemas {
+		if err := schema.IsValid(); err != nil {
+			return fmt.Errorf("schemas[%d].IsValid: %w", i, err)
+		}
+		if schema.Default != nil {
+			defaults[schema.Key] = schema.Default
+		}
+	}
+
+	r.Lock()
+	for _, schema := range schemas {
+		i := slices.IndexFunc(r.schemas, func(registered *Schema) bool {
+			return strings.EqualFold(registered.Key, schema.Key)
+		})
+		if i == -1 {
+			r.schemas = append(r.schemas, schema)
+		} else {
+			r.schemas[i] = schema
+		}
+	}
+	r.Unlock()
+
+	configEngine.SetDefaults(defaults)
+
+	return nil
+}
+
+// registered returns true if the schema of the parameter is registered
+func (r *registry) registered(name string) bool {
+	return slices.ContainsFunc(r.list(), func(schema *Schema) bool {
+		return strings.EqualFold(schema.Key, name)
+	})
+}
+
+// list returns the copy of the registered schemas
+func (r *registry) list() []*Schema {
+	r.RLock()
+	defer r.RUnlock()
+	return slices.Clone(r.schemas)
+}
+
+// problems returns the problems of the registered parameters.
+// The get function returns the parameter value.
+func (r *registry) problems(get func(name string) (interface{}, error)) []*Problem {
+	problems := make([]*Problem, 0)
+
+	for _, schema := range r.list() {
+		raw, err := get(schema.Key)
+		if err != nil {
+			problems = append(problems, &Problem{Key: schema.Key, Reason: err.Error()})
+			continue
+		}
+
+		if raw == nil {
+			if schema.Required {
+				problems = append(problems, &Problem{Key: schema.Key, Reason: "required parameter is missing"})
+			}
+			continue
+		}
+
+		if reason := schema.check(raw); len(reason) > 0 {
+			problems = append(problems, &Problem{Key: schema.Key, Reason: reason})
+		}
+	}
+
+	return problems
+}
+
+// Register the parameter schemas.
+// The schema with the same key replaces the former one.
+// The default values of the schemas are set as the default parameters by SetDefaults.
+func (config *Dev) Register(schemas ...*Schema) error {
+	return config.registry.register(config, schemas...)
+}
+
+// Schemas returns the registered parameter schemas
+func (config *Dev) Schemas() []*Schema {
+	return config.registry.list()
+}
+
+// Validate all registered parameters and the command line flags.
+// Returns *ValidationError that lists every problem.
+func (config *Dev) Validate() error {
+	problems := config.registry.problems(config.Interpolate)
+
+	if err := config.ValidateFlags(); err != nil {
+		if flagsErr, ok := err.(*UnknownFlagsError); ok {
+			for _, flag := range flagsErr.Flags {
+				problems = append(problems, &Problem{Key: arg.NewFlag(flag), Reason: "unknown flag"})
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// Secret references in the parameters:
+//
+//	DB_PASSWORD=secret://db/password
+//
+// The reference is resolved by the SecretProvider when the parameter is read.
+//
+
+// SecretScheme is the prefix of the secret reference
+const SecretScheme = "secret://"
+
+// SecretProvider returns the secrets by the path, for example a vault.
+type SecretProvider interface {
+	// Secret returns the value of the secret.
+	// Returns ErrNotFound if the secret doesn't exist.
+	Secret(secretPath string) (string, error)
+}
+
+// SecretError is returned if the secret reference of the parameter can't be resolved
+type SecretError struct {
+	Name string
+	Err  error
+}
+
+func (e *SecretError) Error() string {
+	return fmt.Sprintf("'%s' secret: %v", e.Name, e.Err)
+}
+
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
+// IsSecretRef returns true if the value is the secret reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretScheme)
+}
+
+// DirSecretProvider keeps one secret per file in the directory.
+// The secret path is the file path relative to the directory: secret://db/password is in <Dir>/db/password.
+//
+// The secret files must not be accessible by the group and other users.
+type DirSecretProvider struct {
+	Dir string
+}
+
+// NewDirSecretProvider returns the secret provider on the directory.
+// The directory must exist.
+func NewDirSecretProvider(dir string) (*DirSecretProvider, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.Stat('%s'): %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", dir)
+	}
+
+	return &DirSecretProvider{Dir: dir}, nil
+}
+
+// Secret returns the content of the secret file without the trailing new line.
+func (provider *DirSecretProvider) Secret(secretPath string) (string, error) {
+	cleanPath := filepath.Clean(filepath.FromSlash(secretPath))
+	if len(secretPath) == 0 || filepath.IsAbs(cleanPath) || cleanPath == ".." ||
+		strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' secret path must be relative to the directory", secretPath)
+	}
+	filePath := filepath.Join(provider.Dir, cleanPath)
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: secret '%s'", ErrNotFound, secretPath)
+		}
+		return "", fmt.Errorf("os.Stat('%s'): %w", filePath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%w: secret '%s' is a directory", ErrNotFound, secretPath)
+	}
+	// windows has no unix permissions
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("secret '%s' file is accessible by other users, change the permission to 0600", secretPath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("os.ReadFile('%s'): %w", filePath, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// secretEntry is the cached secret
+type secretEntry struct {
+	value   string
+	expires time.Time
+}
+
+// secretCache keeps the resolved secrets for the ttl
+type secretCache struct {
+	sync.Mutex
+	provider SecretProvider
+	ttl      time.Duration
+	entries  map[string]*secretEntry
+}
+
+// get returns the cached secret, or resolves it by the provider
+func (cache *secretCache) get(secretPath string) (string, error) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	if entry, ok := cache.entries[secretPath]; ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := cache.provider.Secret(secretPath)
+	if err != nil {
+		delete(cache.entries, secretPath)
+		return "", fmt.Errorf("provider.Secret('%s'): %w", secretPath, err)
+	}
+	cache.entries[secretPath] = &secretEntry{value: value, expires: time.Now().Add(cache.ttl)}
+
+	return value, nil
+}
+
+// redact replaces the cached secrets in the text
+func (cache *secretCache) redact(text string) string {
+	cache.Lock()
+	defer cache.Unlock()
+
+	for _, entry := range cache.entries {
+		if len(entry.value) > 0 {
+			text = strings.ReplaceAll(text, entry.value, Redacted)
+		}
+	}
+
+	return text
+}
+
+// SetSecretProvider sets the provider that resolves the secret references.
+// The resolved secrets are cached for the ttl, if the ttl is 0, then the secrets are not cached.
+func (config *Dev) SetSecretProvider(provider SecretProvider, ttl time.Duration) error {
+	if provider == nil {
+		return fmt.Errorf("provider is nil")
+	}
+	if ttl < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+
+	secrets := &secretCache{
+		provider: provider,
+		ttl:      ttl,
+		entries:  make(map[string]*secretEntry),
+	}
+
+	config.mu.Lock()
+	config.secrets = secrets
+	config.mu.Unlock()
+	return nil
+}
+
+// secretCache returns the cache of the secret provider, nil if the provider is not set
+func (config *Dev) secretCache() *secretCache {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	return config.secrets
+}
+
+// Redact replaces the secrets resolved by the secret provider in the text.
+// Use it before writing the text into the logs.
+func (config *Dev) Redact(text string) string {
+	secrets := config.secretCache()
+	if secrets == nil {
+		return text
+	}
+	return secrets.redact(text)
+}
+
+// secret resolves the secret reference of the parameter.
+// Returns *SecretError if the secret provider is not set or fails.
+func (config *Dev) secret(name string, ref string) (string, error) {
+	secrets := config.secretCache()
+	if secrets == nil {
+		return "", &SecretError{Name: name, Err: errors.New("the secret provider is not set")}
+	}
+
+	value, err := secrets.get(strings.TrimPrefix(ref, SecretScheme))
+	if err != nil {
+		return "", &SecretError{Name: name, Err: err}
+	}
+	return value, nil
+}
+
+// secretRef returns true if the parameter refers to the secret.
+// The export redacts such parameters.
+func (config *Dev) secretRef(name string) bool {
+	str, ok := config.raw(name).(string)
+	return ok && IsSecretRef(str)
+}
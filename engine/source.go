@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// SourceKind defines where the parameter came from
+type SourceKind string
+
+const (
+	// DefaultSource parameters are set by SetDefault or SetDefaults
+	DefaultSource SourceKind = "default"
+	// RemoteSource parameters are loaded from the Backend, the path is the backend key
+	RemoteSource SourceKind = "remote"
+	// ParamFileSource parameters are loaded from the parameter files
+	ParamFileSource SourceKind = "param_file"
+	// EnvFileSource parameters are loaded from the .env files
+	EnvFileSource SourceKind = "env_file"
+	// EnvSource parameters are the environment variables of the process
+	EnvSource SourceKind = "env"
+	// FlagSource parameters are bound from the command line flags
+	FlagSource SourceKind = "flag"
+	// SetSource parameters are set by Set at runtime.
+	// If the parameter is persisted, then the path of the overrides file is set.
+	SetSource SourceKind = "set"
+)
+
+// Source of the parameter value.
+//
+// Fields
+//   - Kind of the source
+//   - Path of the file for the ParamFileSource, EnvFileSource and persisted SetSource
+//   - Line in the file for the EnvFileSource
+type Source struct {
+	Kind SourceKind `json:"kind" yaml:"kind"`
+	Path string     `json:"path,omitempty" yaml:"path,omitempty"`
+	Line int        `json:"line,omitempty" yaml:"line,omitempty"`
+}
+
+func (source *Source) String() string {
+	if len(source.Path) == 0 {
+		return string(source.Kind)
+	}
+	if source.Line == 0 {
+		return fmt.Sprintf("%s (%s)", source.Kind, source.Path)
+	}
+	return fmt.Sprintf("%s (%s:%d)", source.Kind, source.Path, source.Line)
+}
+
+// Source returns where the parameter value came from.
+// Returns ErrNotFound if the parameter is not set.
+//
+// The sources are checked in the order of the precedence.
+func (config *Dev) Source(name string) (*Source, error) {
+	key := strings.ToLower(name)
+
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+
+	if value, ok := config.overrides[key]; ok {
+		source := *value.source
+		return &source, nil
+	}
+
+	if _, ok := config.flags[key]; ok {
+		return &Source{Kind: FlagSource}, nil
+	}
+
+	if envName, ok := config.envName(name); ok {
+		value := os.Getenv(envName)
+		if loaded, ok := config.dotEnv[envName]; ok && loaded.value == value {
+			source := *loaded.source
+			return &source, nil
+		}
+		return &Source{Kind: EnvSource}, nil
+	}
+
+	if filePath, ok := config.fileKeys[key]; ok {
+		return &Source{Kind: ParamFileSource, Path: filePath}, nil
+	}
+
+	if value := config.remoteParam(key); value != nil {
+		source := *value.source
+		return &source, nil
+	}
+
+	if config.Viper.Get(name) != nil {
+		return &Source{Kind: DefaultSource}, nil
+	}
+
+	return nil, fmt.Errorf("%w: '%s'", ErrNotFound, name)
+}
+
+// Keys returns the names of all parameters in the engine sorted alphabetically:
+// the defaults, schemas, backends, parameter files, .env files, flags and overrides.
+//
+// The environment variables of the process are included only if they shadow one of those parameters,
+// so the export doesn't leak the rest of the process environment.
+// The keys are case-insensitive, so the environment variable name is preferred.
+func (config *Dev) Keys() []string {
+	names := make(map[string]string)
+
+	config.mu.RLock()
+	for _, key := range config.Viper.AllKeys() {
+		names[key] = key
+	}
+	for key, value := range config.remote {
+		names[key] = value.name
+	}
+	for key := range config.fileKeys {
+		names[key] = key
+	}
+	for key, name := range config.names {
+		names[key] = name
+	}
+	for name := range config.dotEnv {
+		names[strings.ToLower(name)] = name
+	}
+	config.mu.RUnlock()
+	for _, schema := range config.registry.list() {
+		key := strings.ToLower(schema.Key)
+		if _, ok := names[key]; !ok {
+			names[key] = schema.Key
+		}
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		// the environment variable shadowing the parameter
+		if envName := strings.ToUpper(name); envName != name {
+			if value, ok := os.LookupEnv(envName); ok && len(value) > 0 {
+				name = envName
+			}
+		}
+		keys = append(keys, name)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// Sources returns the source of every parameter in the engine.
+func (config *Dev) Sources() map[string]*Source {
+	keys := config.Keys()
+	sources := make(map[string]*Source, len(keys))
+	for _, key := range keys {
+		source, err := config.Source(key)
+		if err != nil {
+			continue
+		}
+		sources[key] = source
+	}
+
+	return sources
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	"os"
+	"strings"
+)
+
+//
+// Nested parameters.
+//
+// The parameter name is split into the path by the dots and the key separator.
+// For example, the DB__HOST environment variable and the nested
+//
+//	db:
+//	  host: localhost
+//
+// in the parameter file are both the host parameter in the db group.
+//
+
+// DefaultKeySeparator splits the flat parameter names, such as the environment variables, into the path
+const DefaultKeySeparator = "__"
+
+// Grouper is the engine that returns the nested parameters
+type Grouper interface {
+	// Tree returns the parameters under the prefix as the nested maps
+	Tree(prefix string) key_value.KeyValue
+}
+
+var (
+	_ Grouper = (*Dev)(nil)
+	_ Grouper = (*Memory)(nil)
+)
+
+// KeyPath returns the path of the parameter name split by the dots and the separator.
+// The path is lowercase, the empty parts are skipped.
+func KeyPath(name string, separator string) []string {
+	key := strings.ToLower(name)
+	if len(separator) > 0 {
+		key = strings.ReplaceAll(key, strings.ToLower(separator), ".")
+	}
+
+	parts := strings.Split(key, ".")
+	path := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if len(part) > 0 {
+			path = append(path, part)
+		}
+	}
+
+	return path
+}
+
+// Tree returns the parameters of the engine under the prefix as the nested maps.
+// The empty prefix returns all parameters.
+//
+// If the parameter is both a value and a group, for example DB and DB__HOST, then the group is kept.
+func Tree(configEngine Interface, prefix string, separator string) key_value.KeyValue {
+	prefixPath := KeyPath(prefix, separator)
+	tree := key_value.New()
+
+	for _, name := range configEngine.Keys() {
+		path := KeyPath(name, separator)
+		if len(path) <= len(prefixPath) || !hasPrefix(path, prefixPath) {
+			continue
+		}
+		value := configEngine.Get(name)
+		if value == nil {
+			continue
+		}
+
+		group := tree
+		for _, part := range path[len(prefixPath) : len(path)-1] {
+			child, ok := group[part].(key_value.KeyValue)
+			if !ok {
+				child = key_value.New()
+				group[part] = child
+			}
+			group = child
+		}
+
+		leaf := path[len(path)-1]
+		if _, ok := group[leaf].(key_value.KeyValue); ok {
+			continue
+		}
+		group[leaf] = value
+	}
+
+	return tree
+}
+
+// hasPrefix returns true if the path starts with the prefix path
+func hasPrefix(path []string, prefix []string) bool {
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// separator returns the key separator of the engine
+func (config *Dev) separator() string {
+	if len(config.KeySeparator) == 0 {
+		return DefaultKeySeparator
+	}
+	return config.KeySeparator
+}
+
+// envName returns the environment variable of the parameter.
+// The dots in the name of the nested parameter are replaced by the key separator.
+// Returns false if the environment variable is not set.
+func (config *Dev) envName(name string) (string, bool) {
+	envName := strings.ToUpper(name)
+	if value, ok := os.LookupEnv(envName); ok && len(value) > 0 {
+		return envName, true
+	}
+	if !strings.Contains(name, ".") {
+		return "", false
+	}
+
+	envName = strings.ReplaceAll(envName, ".", strings.ToUpper(config.separator()))
+	if value, ok := os.LookupEnv(envName); ok && len(value) > 0 {
+		return envName, true
+	}
+	return "", false
+}
+
+// Tree returns the parameters under the prefix as the nested maps.
+// The flat names are split by the KeySeparator, for example Tree("db") returns DB__HOST as the host.
+func (config *Dev) Tree(prefix string) key_value.KeyValue {
+	return Tree(config, prefix, config.separator())
+}
+
+// Tree returns the parameters under the prefix as the nested maps.
+// The flat names are split by the KeySeparator, for example Tree("db") returns DB__HOST as the host.
+func (m *Memory) Tree(prefix string) key_value.KeyValue {
+	separator := m.KeySeparator
+	if len(separator) == 0 {
+		separator = DefaultKeySeparator
+	}
+	return Tree(m, prefix, separator)
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	"strconv"
+	"time"
+)
+
+//
+// Type-preserving transport of the parameters.
+//
+// The socket messages are JSON, so the numbers arrive as float64 or json.Number
+// and the lists and maps arrive as the generic interfaces.
+// The value is sent with its Kind to restore the Go type on the other side.
+// The integers and durations are sent as strings to keep the precision.
+//
+
+// KindOf returns the kind of the Go value.
+// Returns an error if the type doesn't match any kind.
+func KindOf(value interface{}) (Kind, error) {
+	switch value.(type) {
+	case string:
+		return StringKind, nil
+	case bool:
+		return BoolKind, nil
+	case time.Duration:
+		return DurationKind, nil
+	case uint, uint8, uint16, uint32, uint64:
+		return Uint64Kind, nil
+	case int, int8, int16, int32, int64:
+		return Int64Kind, nil
+	case float32, float64:
+		return Float64Kind, nil
+	case []string:
+		return StringSliceKind, nil
+	case map[string]string:
+		return StringMapKind, nil
+	}
+
+	return "", fmt.Errorf("%T type has no parameter kind", value)
+}
+
+// EncodeKind returns the kind of the value and the value to send in the JSON message.
+// Returns an error if the type doesn't match any kind.
+func EncodeKind(value interface{}) (Kind, interface{}, error) {
+	kind, err := KindOf(value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch kind {
+	case DurationKind:
+		return kind, value.(time.Duration).String(), nil
+	case Uint64Kind, Int64Kind:
+		encoded, err := ToString(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("ToString: %w", err)
+		}
+		return kind, encoded, nil
+	}
+
+	return kind, value, nil
+}
+
+// DecodeKind converts the value received in the JSON message to the Go type of the kind:
+// string, bool, uint64, int64, float64, time.Duration, []string or map[string]string.
+//
+// Returns ErrConversion if the value doesn't match the kind.
+func DecodeKind(kind Kind, raw interface{}) (interface{}, error) {
+	if err := ValidateKind(kind); err != nil {
+		return nil, fmt.Errorf("ValidateKind: %w", err)
+	}
+	switch received := raw.(type) {
+	case key_value.KeyValue:
+		raw = map[string]interface{}(received)
+	case json.Number:
+		raw = received.String()
+	}
+
+	var value interface{}
+	var err error
+	switch kind {
+	case StringKind:
+		value, err = ToString(raw)
+	case BoolKind:
+		value, err = ToBool(raw)
+	case Uint64Kind:
+		// cast parses the strings as int64, so the values above math.MaxInt64 are parsed here
+		if str, ok := raw.(string); ok {
+			value, err = strconv.ParseUint(str, 10, 64)
+		} else {
+			value, err = ToUint64(raw)
+		}
+	case Int64Kind:
+		value, err = ToInt64(raw)
+	case Float64Kind:
+		value, err = ToFloat64(raw)
+	case DurationKind:
+		value, err = ToDuration(raw)
+	case StringSliceKind:
+		value, err = ToStringSlice(raw)
+	case StringMapKind:
+		value, err = ToStringMap(raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v to %s: %v", ErrConversion, raw, kind, err)
+	}
+
+	return value, nil
+}
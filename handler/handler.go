@@ -3,43 +3,80 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/ahmetson/config-lib/app"
 	"github.com/ahmetson/config-lib/engine"
 	"github.com/ahmetson/config-lib/service"
+	"github.com/ahmetson/config-lib/watch"
 	"github.com/ahmetson/datatype-lib/data_type/key_value"
 	"github.com/ahmetson/datatype-lib/message"
 	"github.com/ahmetson/handler-lib/base"
 	handlerConfig "github.com/ahmetson/handler-lib/config"
+	"github.com/ahmetson/handler-lib/manager_client"
 	"github.com/ahmetson/handler-lib/replier"
 	"github.com/ahmetson/log-lib"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
-	Id              = "dev_config_handler" // only one instance of config Engine can be in the service
-	ServiceById     = "service"
-	ServiceByUrl    = "service-by-url"
-	ServiceExist    = "service-exist"
-	SetService      = "set-service"
-	ParamExist      = "param-exist"
-	StringParam     = "string-param"
-	Uint64Param     = "uint64-param"
-	BoolParam       = "bool-param"
-	GenerateHandler = "generate-handler"
-	SetDefaultParam = "set-default"
-	GenerateService = "generate-service"
+	Id               = "dev_config_handler" // only one instance of config Engine can be in the service
+	ServiceById      = "service"
+	ServiceByUrl     = "service-by-url"
+	ServiceExist     = "service-exist"
+	SetService       = "set-service"
+	ParamExist       = "param-exist"
+	StringParam      = "string-param"
+	Uint64Param      = "uint64-param"
+	BoolParam        = "bool-param"
+	Float64Param     = "float64-param"
+	Int64Param       = "int64-param"
+	DurationParam    = "duration-param"
+	StringSliceParam = "string-slice-param"
+	StringMapParam   = "string-map-param"
+	GenerateHandler  = "generate-handler"
+	SetDefaultParam  = "set-default"
+	GenerateService  = "generate-service"
+	RegisterSchema   = "register-schema"
+	ValidateParams   = "validate-params"
+	ParamSource      = "param-source"
+	ParamSources     = "param-sources"
+	SetParam         = "set-param"
+	UnsetParam       = "unset-param"
+	ExportParams     = "export-params"
+	BindParams       = "bind-params"
+	ParamTree        = "param-tree"
+	SetServiceParam  = "set-service-param"
+	SetDefaultParams = "set-defaults"
+	RestoreApp       = "restore-app"
+)
+
+// The codes of the failed parameter reads, replied as the 'code' reply parameter
+const (
+	NotFoundCode      = "not_found"     // engine.ErrNotFound
+	ConversionCode    = "conversion"    // engine.ErrConversion
+	InterpolationCode = "interpolation" // engine.ErrInterpolation
 )
 
 type Handler struct {
-	Engine   *engine.Dev // todo make it private, for now it's used in the tests of other packages
+	Engine   engine.Interface // todo make it private, for now it's used in the tests of other packages
 	app      *app.App
 	filePath string
 	handler  base.Interface
+	logger   *log.Logger
+	cancel   context.CancelFunc // stops reloading the files
 }
 
 // New handler of the config.
 // The handler is initialized for use.
-func New() (*Handler, error) {
+//
+// Optionally, the configuration engine is passed, for example engine.NewMemory() in the tests.
+// By default, the handler uses engine.Dev.
+func New(engines ...engine.Interface) (*Handler, error) {
 	h := &Handler{}
 
 	logger, err := log.New("config", false)
@@ -47,16 +84,31 @@ func New() (*Handler, error) {
 		return nil, fmt.Errorf("log.New('config'): %w", err)
 	}
 
-	dev, err := engine.NewDev()
-	if err != nil {
-		return nil, fmt.Errorf("Engine.NewDev: %w", err)
+	switch len(engines) {
+	case 0:
+		dev, err := engine.NewDev()
+		if err != nil {
+			return nil, fmt.Errorf("Engine.NewDev: %w", err)
+		}
+		h.Engine = dev
+	case 1:
+		if engines[0] == nil {
+			return nil, fmt.Errorf("the engine is nil")
+		}
+		h.Engine = engines[0]
+	default:
+		return nil, fmt.Errorf("only one engine is allowed, given %d", len(engines))
 	}
-	h.Engine = dev
 
-	filePath, fileExist, err := app.ReadFileParameters(dev)
+	filePath, fileExist, err := app.ReadFileParameters(h.Engine)
 	if err != nil {
 		return nil, fmt.Errorf("app.ReadFileParameters: %w", err)
 	}
+	if persister, ok := h.Engine.(engine.Persister); ok {
+		if err := persister.LoadOverrides(app.OverridesPath(filePath)); err != nil {
+			return nil, fmt.Errorf("Engine.LoadOverrides: %w", err)
+		}
+	}
 	h.app = app.New()
 	h.filePath = filePath
 
@@ -65,6 +117,9 @@ func New() (*Handler, error) {
 		if err := app.Read(filePath, h.app); err != nil {
 			return nil, fmt.Errorf("read('%s'): %w", filePath, err)
 		}
+		if err := h.app.Validate(); err != nil {
+			return nil, fmt.Errorf("app.Validate('%s'): %w", filePath, err)
+		}
 		if err := h.app.RegisterId(); err != nil {
 			return nil, fmt.Errorf(fmt.Sprintf("app.RegisterId: %v", err))
 		}
@@ -72,6 +127,7 @@ func New() (*Handler, error) {
 		return nil, fmt.Errorf("handler.writeInitialApp: %w", err)
 	}
 
+	h.logger = logger
 	h.handler = replier.New()
 	h.handler.SetConfig(SocketConfig())
 	if err := h.handler.SetLogger(logger); err != nil {
@@ -93,8 +149,26 @@ func (handler *Handler) writeInitialApp() error {
 		return fmt.Errorf("app.MakeConfigDir('%s'): %w", handler.filePath, err)
 	}
 
-	if err := app.Write(handler.filePath, handler.app); err != nil {
-		return fmt.Errorf("app.Write('%s', 'h.app'): %w", handler.filePath, err)
+	// another process could write it in the meantime
+	if err := handler.update(func(*app.App) error { return nil }); err != nil {
+		return fmt.Errorf("handler.update('%s'): %w", handler.filePath, err)
+	}
+
+	return nil
+}
+
+// update the app configuration in the file, keeping the changes of the other processes.
+// The updated app configuration is used by the handler.
+//
+// On *app.ConflictError the latest app configuration is used,
+// so the caller could retry the change over it.
+func (handler *Handler) update(fn func(current *app.App) error) error {
+	updated, err := app.Update(handler.filePath, handler.app, fn)
+	if updated != nil {
+		handler.app = updated
+	}
+	if err != nil {
+		return fmt.Errorf("app.Update: %w", err)
 	}
 
 	return nil
@@ -122,6 +196,21 @@ func (handler *Handler) setRoutes() error {
 	if err := handler.handler.Route(BoolParam, handler.onBool); err != nil {
 		return fmt.Errorf("handler.Route(%s): %w", BoolParam, err)
 	}
+	if err := handler.handler.Route(Float64Param, handler.onFloat64); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", Float64Param, err)
+	}
+	if err := handler.handler.Route(Int64Param, handler.onInt64); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", Int64Param, err)
+	}
+	if err := handler.handler.Route(DurationParam, handler.onDuration); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", DurationParam, err)
+	}
+	if err := handler.handler.Route(StringSliceParam, handler.onStringSlice); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", StringSliceParam, err)
+	}
+	if err := handler.handler.Route(StringMapParam, handler.onStringMap); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", StringMapParam, err)
+	}
 	if err := handler.handler.Route(GenerateHandler, handler.onGenerateHandler); err != nil {
 		return fmt.Errorf("handler.Route(%s): %w", GenerateHandler, err)
 	}
@@ -134,6 +223,42 @@ func (handler *Handler) setRoutes() error {
 	if err := handler.handler.Route(GenerateService, handler.onGenerateService); err != nil {
 		return fmt.Errorf("handler.Route(%s): %w", GenerateService, err)
 	}
+	if err := handler.handler.Route(RegisterSchema, handler.onRegisterSchema); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", RegisterSchema, err)
+	}
+	if err := handler.handler.Route(ValidateParams, handler.onValidateParams); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", ValidateParams, err)
+	}
+	if err := handler.handler.Route(ParamSource, handler.onParamSource); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", ParamSource, err)
+	}
+	if err := handler.handler.Route(ParamSources, handler.onParamSources); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", ParamSources, err)
+	}
+	if err := handler.handler.Route(SetParam, handler.onSetParam); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", SetParam, err)
+	}
+	if err := handler.handler.Route(UnsetParam, handler.onUnsetParam); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", UnsetParam, err)
+	}
+	if err := handler.handler.Route(ExportParams, handler.onExportParams); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", ExportParams, err)
+	}
+	if err := handler.handler.Route(BindParams, handler.onBindParams); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", BindParams, err)
+	}
+	if err := handler.handler.Route(ParamTree, handler.onParamTree); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", ParamTree, err)
+	}
+	if err := handler.handler.Route(SetServiceParam, handler.onSetServiceParam); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", SetServiceParam, err)
+	}
+	if err := handler.handler.Route(SetDefaultParams, handler.onSetDefaults); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", SetDefaultParams, err)
+	}
+	if err := handler.handler.Route(RestoreApp, handler.onRestoreApp); err != nil {
+		return fmt.Errorf("handler.Route(%s): %w", RestoreApp, err)
+	}
 
 	return nil
 }
@@ -255,31 +380,112 @@ func (handler *Handler) onSetService(req message.RequestInterface) message.Reply
 		return req.Fail(fmt.Sprintf("s.ValidateTypes: %v", err))
 	}
 
-	err = handler.app.SetService(&s)
+	err = handler.update(func(current *app.App) error {
+		if err := current.SetService(&s); err != nil {
+			return fmt.Errorf("app.SetService: %w", err)
+		}
+
+		_ = current.SetId(s.Id)
+		for _, h := range s.Handlers {
+			_ = current.SetId(h.Id)
+		}
+		return nil
+	})
 	if err != nil {
-		return req.Fail(fmt.Sprintf("app.SetService: %v", err))
+		return req.Fail(fmt.Sprintf("handler.update: %v", err))
 	}
 
-	_ = handler.app.SetId(s.Id)
-	for _, h := range s.Handlers {
-		_ = handler.app.SetId(h.Id)
+	return req.Ok(key_value.New())
+}
+
+// onRestoreApp replaces the app configuration with the 'backup'.
+// The first backup is the latest version before the last write.
+// The backup that fails the validation is not restored.
+func (handler *Handler) onRestoreApp(req message.RequestInterface) message.ReplyInterface {
+	backup, err := req.RouteParameters().Uint64Value("backup")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.Uint64Value('backup'): %v", err))
 	}
 
-	if err := app.Write(handler.filePath, handler.app); err != nil {
-		return req.Fail(fmt.Sprintf("app.Write: %v", err))
+	// the backup is checked before it replaces the app configuration
+	restored := app.New()
+	if err := app.ReadBackup(handler.filePath, int(backup), restored); err != nil {
+		return req.Fail(fmt.Sprintf("app.ReadBackup('%s', %d): %v", handler.filePath, backup, err))
+	}
+	if err := restored.Validate(); err != nil {
+		return req.Fail(fmt.Sprintf("app.Validate: %v", err))
+	}
+	if err := restored.RegisterId(); err != nil {
+		return req.Fail(fmt.Sprintf("app.RegisterId: %v", err))
+	}
+
+	if err := app.Restore(handler.filePath, int(backup)); err != nil {
+		return req.Fail(fmt.Sprintf("app.Restore: %v", err))
+	}
+	handler.app = restored
+
+	return req.Ok(key_value.New())
+}
+
+// onSetServiceParam sets the custom parameter of the 'service'.
+// If the optional 'handler' route parameter is given, then the parameter is set for the handler.
+// The parameter is persisted in the app configuration.
+func (handler *Handler) onSetServiceParam(req message.RequestInterface) message.ReplyInterface {
+	id, err := req.RouteParameters().StringValue("service")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('service'): %v", err))
+	}
+	name, err := req.RouteParameters().StringValue("name")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+	}
+	value, err := routeValue(req)
+	if err != nil {
+		return req.Fail(err.Error())
+	}
+
+	handlerId := ""
+	if req.RouteParameters().Exist("handler") {
+		handlerId, err = req.RouteParameters().StringValue("handler")
+		if err != nil {
+			return req.Fail(fmt.Sprintf("req.Parameters.StringValue('handler'): %v", err))
+		}
+	}
+
+	err = handler.update(func(current *app.App) error {
+		s := current.Service(id)
+		if s == nil {
+			return fmt.Errorf("service('%s') not found", id)
+		}
+		if len(handlerId) == 0 {
+			s.SetParam(name, value)
+			return nil
+		}
+		if err := s.SetHandlerParam(handlerId, name, value); err != nil {
+			return fmt.Errorf("service.SetHandlerParam: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return req.Fail(fmt.Sprintf("handler.update: %v", err))
 	}
 
 	return req.Ok(key_value.New())
 }
 
 // onExist checks is the given 'name' exists in the configuration.
+// The parameter is scoped by the optional 'service' and 'handler' route parameters.
 func (handler *Handler) onExist(req message.RequestInterface) message.ReplyInterface {
 	name, err := req.RouteParameters().StringValue("name")
 	if err != nil {
 		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
 	}
 
-	exist := handler.Engine.Exist(name)
+	raw, err := handler.scopedParam(req, name, false)
+	if err != nil {
+		return req.Fail(err.Error())
+	}
+	exist := raw != nil
 
 	param := key_value.New().Set("exist", exist)
 	return req.Ok(param)
@@ -291,9 +497,9 @@ func (handler *Handler) onSetDefault(req message.RequestInterface) message.Reply
 	if err != nil {
 		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
 	}
-	value, ok := req.RouteParameters()["value"]
-	if !ok {
-		return req.Fail("req.Parameters['value'] not found")
+	value, err := routeValue(req)
+	if err != nil {
+		return req.Fail(err.Error())
 	}
 
 	handler.Engine.SetDefault(name, value)
@@ -302,6 +508,299 @@ func (handler *Handler) onSetDefault(req message.RequestInterface) message.Reply
 	return req.Ok(param)
 }
 
+// onSetDefaults sets the default 'params' in the Engine at once.
+// The optional 'types' route parameter keeps the kind of the parameters by the name, see routeValue.
+//
+// Returns the 'problems' list of the parameters that could not be set.
+// If there are any problems, then none of the parameters is set.
+// Returns the 'skipped' list of the parameters that kept their values, since they are set already.
+func (handler *Handler) onSetDefaults(req message.RequestInterface) message.ReplyInterface {
+	params, err := req.RouteParameters().NestedValue("params")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.NestedValue('params'): %v", err))
+	}
+	types := key_value.New()
+	if req.RouteParameters().Exist("types") {
+		types, err = req.RouteParameters().NestedValue("types")
+		if err != nil {
+			return req.Fail(fmt.Sprintf("req.Parameters.NestedValue('types'): %v", err))
+		}
+	}
+
+	defaulter, ok := handler.Engine.(engine.Defaulter)
+	if !ok {
+		return req.Fail(unsupported("batch defaults"))
+	}
+
+	problems := make([]*engine.Problem, 0)
+	defaults := key_value.New()
+	for name, value := range params {
+		if value == nil {
+			problems = append(problems, &engine.Problem{Key: name, Reason: "value is nil"})
+			continue
+		}
+		if types.Exist(name) {
+			kind, err := types.StringValue(name)
+			if err != nil {
+				problems = append(problems, &engine.Problem{Key: name, Reason: fmt.Sprintf("types.StringValue: %v", err)})
+				continue
+			}
+			value, err = engine.DecodeKind(engine.Kind(kind), value)
+			if err != nil {
+				problems = append(problems, &engine.Problem{Key: name, Reason: err.Error()})
+				continue
+			}
+		}
+		defaults[name] = value
+	}
+	slices.SortFunc(problems, func(a, b *engine.Problem) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	skipped := make([]string, 0)
+	if len(problems) == 0 {
+		skipped = defaulter.SetDefaults(defaults)
+	}
+
+	return req.Ok(key_value.New().Set("problems", problems).Set("skipped", skipped))
+}
+
+// onSetParam overrides the parameter in the Engine at runtime.
+// The overrides are persisted next to the app configuration.
+// If the overrides could not be persisted, then the parameter is rolled back.
+func (handler *Handler) onSetParam(req message.RequestInterface) message.ReplyInterface {
+	name, err := req.RouteParameters().StringValue("name")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+	}
+	value, err := routeValue(req)
+	if err != nil {
+		return req.Fail(err.Error())
+	}
+
+	persister, ok := handler.Engine.(engine.Persister)
+	if !ok {
+		handler.Engine.Set(name, value)
+		return req.Ok(key_value.New())
+	}
+
+	previous, overridden := persister.Override(name)
+	handler.Engine.Set(name, value)
+	if err := persister.WriteOverrides(); err != nil {
+		handler.restoreOverride(name, previous, overridden)
+		return req.Fail(fmt.Sprintf("Engine.WriteOverrides: %v", err))
+	}
+
+	return req.Ok(key_value.New())
+}
+
+// restoreOverride rolls back the parameter set at runtime, when the change could not be persisted.
+func (handler *Handler) restoreOverride(name string, previous interface{}, overridden bool) {
+	if overridden {
+		handler.Engine.Set(name, previous)
+		return
+	}
+	if unsetter, ok := handler.Engine.(engine.Unsetter); ok {
+		unsetter.Unset(name)
+	}
+}
+
+// onUnsetParam removes the runtime override of the parameter from the Engine.
+// If the overrides could not be persisted, then the override is restored.
+//
+// Returns 'unset' parameter. It's false if the parameter was not overridden.
+func (handler *Handler) onUnsetParam(req message.RequestInterface) message.ReplyInterface {
+	name, err := req.RouteParameters().StringValue("name")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+	}
+
+	unsetter, ok := handler.Engine.(engine.Unsetter)
+	if !ok {
+		return req.Fail(unsupported("unset"))
+	}
+
+	persister, persist := handler.Engine.(engine.Persister)
+	var previous interface{}
+	if persist {
+		previous, _ = persister.Override(name)
+	}
+
+	unset := unsetter.Unset(name)
+	if persist && unset {
+		if err := persister.WriteOverrides(); err != nil {
+			handler.restoreOverride(name, previous, true)
+			return req.Fail(fmt.Sprintf("Engine.WriteOverrides: %v", err))
+		}
+	}
+
+	params := key_value.New().Set("unset", unset)
+	return req.Ok(params)
+}
+
+// onRegisterSchema registers the parameter schemas in the Engine.
+// The default values of the schemas are set as the default parameters.
+func (handler *Handler) onRegisterSchema(req message.RequestInterface) message.ReplyInterface {
+	rawSchemas, err := req.RouteParameters().NestedListValue("schemas")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.NestedListValue('schemas'): %v", err))
+	}
+
+	schemas := make([]*engine.Schema, len(rawSchemas))
+	for i, raw := range rawSchemas {
+		var schema engine.Schema
+		if err := raw.Interface(&schema); err != nil {
+			return req.Fail(fmt.Sprintf("schemas[%d].Interface: %v", i, err))
+		}
+		schemas[i] = &schema
+	}
+
+	registry, ok := handler.Engine.(engine.Registry)
+	if !ok {
+		return req.Fail(unsupported("schemas"))
+	}
+	if err := registry.Register(schemas...); err != nil {
+		return req.Fail(fmt.Sprintf("Engine.Register: %v", err))
+	}
+
+	return req.Ok(key_value.New())
+}
+
+// onValidateParams validates the registered parameters.
+// Returns the 'problems' list. If the parameters are valid, then the list is empty.
+func (handler *Handler) onValidateParams(req message.RequestInterface) message.ReplyInterface {
+	registry, ok := handler.Engine.(engine.Registry)
+	if !ok {
+		return req.Fail(unsupported("schemas"))
+	}
+
+	problems := make([]*engine.Problem, 0)
+
+	err := registry.Validate()
+	if err != nil {
+		validationErr, ok := err.(*engine.ValidationError)
+		if !ok {
+			return req.Fail(fmt.Sprintf("Engine.Validate: %v", err))
+		}
+		problems = validationErr.Problems
+	}
+
+	params := key_value.New().Set("problems", problems)
+	return req.Ok(params)
+}
+
+// onParamSource returns the parameter 'value' and the 'source' where it came from.
+func (handler *Handler) onParamSource(req message.RequestInterface) message.ReplyInterface {
+	name, err := req.RouteParameters().StringValue("name")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+	}
+
+	tracker, ok := handler.Engine.(engine.Tracker)
+	if !ok {
+		return req.Fail(unsupported("sources"))
+	}
+
+	source, err := tracker.Source(name)
+	if err != nil {
+		return req.Fail(fmt.Sprintf("Engine.Source: %v", err))
+	}
+
+	value, err := engine.ToString(handler.Engine.Get(name))
+	if err != nil {
+		return handler.paramFail(req, conversionError(name, "string", err))
+	}
+
+	params := key_value.New().Set("value", value).Set("source", source)
+	return req.Ok(params)
+}
+
+// onParamSources returns the 'sources' of every parameter by the parameter name.
+func (handler *Handler) onParamSources(req message.RequestInterface) message.ReplyInterface {
+	tracker, ok := handler.Engine.(engine.Tracker)
+	if !ok {
+		return req.Fail(unsupported("sources"))
+	}
+
+	params := key_value.New().Set("sources", tracker.Sources())
+	return req.Ok(params)
+}
+
+// onExportParams returns the effective parameters as a 'content' string in the requested 'format'.
+// The secret parameters are redacted.
+//
+// The optional 'secret_patterns' route parameter replaces the engine's secret patterns.
+func (handler *Handler) onExportParams(req message.RequestInterface) message.ReplyInterface {
+	format, err := req.RouteParameters().StringValue("format")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('format'): %v", err))
+	}
+
+	var secretPatterns []string
+	if req.RouteParameters().Exist("secret_patterns") {
+		secretPatterns, err = req.RouteParameters().StringsValue("secret_patterns")
+		if err != nil {
+			return req.Fail(fmt.Sprintf("req.Parameters.StringsValue('secret_patterns'): %v", err))
+		}
+	}
+
+	exporter, ok := handler.Engine.(engine.Exporter)
+	if !ok {
+		return req.Fail(unsupported("export"))
+	}
+
+	content, err := exporter.Export(engine.ExportFormat(format), secretPatterns...)
+	if err != nil {
+		return req.Fail(fmt.Sprintf("Engine.Export('%s'): %v", format, err))
+	}
+
+	params := key_value.New().Set("content", string(content))
+	return req.Ok(params)
+}
+
+// onBindParams returns the raw values of the requested 'names' as the 'params' map.
+// The missing parameters are not included.
+// The parameters are scoped by the optional 'service' and 'handler' route parameters.
+//
+// The client binds the values into the struct.
+func (handler *Handler) onBindParams(req message.RequestInterface) message.ReplyInterface {
+	names, err := req.RouteParameters().StringsValue("names")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringsValue('names'): %v", err))
+	}
+
+	params := key_value.New()
+	for _, name := range names {
+		raw, err := handler.scopedParam(req, name, false)
+		if err != nil {
+			return req.Fail(err.Error())
+		}
+		if raw == nil {
+			continue
+		}
+		params.Set(name, raw)
+	}
+
+	return req.Ok(key_value.New().Set("params", params))
+}
+
+// onParamTree returns the parameters under the 'prefix' as the nested 'tree'.
+// The empty prefix returns all parameters.
+func (handler *Handler) onParamTree(req message.RequestInterface) message.ReplyInterface {
+	prefix, err := req.RouteParameters().StringValue("prefix")
+	if err != nil {
+		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('prefix'): %v", err))
+	}
+
+	grouper, ok := handler.Engine.(engine.Grouper)
+	if !ok {
+		return req.Fail(unsupported("nested parameters"))
+	}
+
+	params := key_value.New().Set("tree", grouper.Tree(prefix))
+	return req.Ok(params)
+}
+
 // onGenerateService generates the service parameters
 //
 // todo write the service into the yaml
@@ -341,51 +840,366 @@ func (handler *Handler) onGenerateService(req message.RequestInterface) message.
 	return req.Ok(params)
 }
 
+// engineParam returns the parameter from the Engine.
+// In the strict mode, if the Engine interpolates the parameters, then the interpolation error is returned.
+func (handler *Handler) engineParam(name string, strict bool) (interface{}, error) {
+	if interpolator, ok := handler.Engine.(engine.Interpolator); ok && strict {
+		value, err := interpolator.Interpolate(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %v", engine.ErrInterpolation, name, err)
+		}
+		return value, nil
+	}
+
+	return handler.Engine.Get(name), nil
+}
+
+// scopedParam returns the parameter of the service given by the optional 'service' route parameter.
+// If the optional 'handler' route parameter is given too, then the handler parameter is preferred.
+// If the service doesn't have the parameter, then the Engine parameter is returned.
+//
+// See engineParam for the strict mode.
+func (handler *Handler) scopedParam(req message.RequestInterface, name string, strict bool) (interface{}, error) {
+	if !req.RouteParameters().Exist("service") {
+		return handler.engineParam(name, strict)
+	}
+
+	id, err := req.RouteParameters().StringValue("service")
+	if err != nil {
+		return nil, fmt.Errorf("req.Parameters.StringValue('service'): %w", err)
+	}
+	s := handler.app.Service(id)
+	if s == nil {
+		return nil, fmt.Errorf("service('%s') not found", id)
+	}
+
+	var value interface{}
+	var ok bool
+	if req.RouteParameters().Exist("handler") {
+		handlerId, err := req.RouteParameters().StringValue("handler")
+		if err != nil {
+			return nil, fmt.Errorf("req.Parameters.StringValue('handler'): %w", err)
+		}
+		value, ok = s.HandlerParam(handlerId, name)
+	} else {
+		value, ok = s.Param(name)
+	}
+	if ok {
+		return value, nil
+	}
+
+	return handler.engineParam(name, strict)
+}
+
+// rawParam returns the 'name' route parameter and the raw value of it.
+// The parameter is scoped by the optional 'service' and 'handler' route parameters.
+//
+// If the optional 'strict' route parameter is true,
+// then the missing parameter returns engine.ErrNotFound,
+// and the parameter that can't be interpolated returns the interpolation error.
+func (handler *Handler) rawParam(req message.RequestInterface) (string, interface{}, bool, error) {
+	name, err := req.RouteParameters().StringValue("name")
+	if err != nil {
+		return "", nil, false, fmt.Errorf("req.Parameters.StringValue('name'): %w", err)
+	}
+
+	strict := false
+	if req.RouteParameters().Exist("strict") {
+		strict, err = req.RouteParameters().BoolValue("strict")
+		if err != nil {
+			return "", nil, false, fmt.Errorf("req.Parameters.BoolValue('strict'): %w", err)
+		}
+	}
+
+	raw, err := handler.scopedParam(req, name, strict)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if raw == nil && strict {
+		return name, nil, strict, fmt.Errorf("%w: '%s'", engine.ErrNotFound, name)
+	}
+
+	return name, raw, strict, nil
+}
+
+// routeValue returns the 'value' route parameter.
+// If the optional 'type' route parameter is given, then the value is converted to the Go type of the kind.
+// See engine.EncodeKind.
+func routeValue(req message.RequestInterface) (interface{}, error) {
+	value, ok := req.RouteParameters()["value"]
+	if !ok {
+		return nil, fmt.Errorf("req.Parameters['value'] not found")
+	}
+	if !req.RouteParameters().Exist("type") {
+		return value, nil
+	}
+
+	kind, err := req.RouteParameters().StringValue("type")
+	if err != nil {
+		return nil, fmt.Errorf("req.Parameters.StringValue('type'): %w", err)
+	}
+	value, err = engine.DecodeKind(engine.Kind(kind), value)
+	if err != nil {
+		return nil, fmt.Errorf("engine.DecodeKind('%s'): %w", kind, err)
+	}
+
+	return value, nil
+}
+
+// unsupported returns the error message for the feature that the Engine doesn't implement.
+func unsupported(feature string) string {
+	return fmt.Sprintf("the engine doesn't support %s", feature)
+}
+
+// conversionError returns the error for the parameter that can not be converted to the type.
+func conversionError(name string, typeName string, err error) error {
+	return fmt.Errorf("%w: '%s' to %s: %v", engine.ErrConversion, name, typeName, err)
+}
+
+// errorCode returns the code of the failed parameter read, empty if the error has no code.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, engine.ErrNotFound):
+		return NotFoundCode
+	case errors.Is(err, engine.ErrConversion):
+		return ConversionCode
+	case errors.Is(err, engine.ErrInterpolation):
+		return InterpolationCode
+	}
+	return ""
+}
+
+// paramFail replies the failed parameter read.
+// The reason is replied as the 'code' parameter, so the client doesn't parse the error message.
+// The resolved secrets are redacted in the error message.
+func (handler *Handler) paramFail(req message.RequestInterface, err error) message.ReplyInterface {
+	reply := req.Fail(handler.redact(err.Error()))
+	if code := errorCode(err); len(code) > 0 {
+		reply.ReplyParameters().Set("code", code)
+	}
+	return reply
+}
+
 // onString returns a string parameter from the Engine.
 func (handler *Handler) onString(req message.RequestInterface) message.ReplyInterface {
-	name, err := req.RouteParameters().StringValue("name")
+	name, raw, strict, err := handler.rawParam(req)
 	if err != nil {
-		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+		return handler.paramFail(req, err)
 	}
 
-	value := handler.Engine.GetString(name)
+	value, err := engine.ToString(raw)
+	if err != nil && strict {
+		return handler.paramFail(req, conversionError(name, "string", err))
+	}
 
 	param := key_value.New().Set("value", value)
 	return req.Ok(param)
 }
 
-// onUint64 returns a string parameter from the Engine.
+// onUint64 returns an uint64 parameter from the Engine.
 func (handler *Handler) onUint64(req message.RequestInterface) message.ReplyInterface {
-	name, err := req.RouteParameters().StringValue("name")
+	name, raw, strict, err := handler.rawParam(req)
 	if err != nil {
-		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+		return handler.paramFail(req, err)
 	}
 
-	value := handler.Engine.GetUint64(name)
+	value, err := engine.ToUint64(raw)
+	if err != nil && strict {
+		return handler.paramFail(req, conversionError(name, "uint64", err))
+	}
 
 	param := key_value.New().Set("value", value)
 	return req.Ok(param)
 }
 
-// onBool returns a string parameter from the Engine.
+// onBool returns a boolean parameter from the Engine.
 func (handler *Handler) onBool(req message.RequestInterface) message.ReplyInterface {
-	name, err := req.RouteParameters().StringValue("name")
+	name, raw, strict, err := handler.rawParam(req)
 	if err != nil {
-		return req.Fail(fmt.Sprintf("req.Parameters.StringValue('name'): %v", err))
+		return handler.paramFail(req, err)
+	}
+
+	value, err := engine.ToBool(raw)
+	if err != nil && strict {
+		return handler.paramFail(req, conversionError(name, "bool", err))
+	}
+
+	param := key_value.New().Set("value", value)
+	return req.Ok(param)
+}
+
+// onFloat64 returns a float64 parameter from the Engine.
+func (handler *Handler) onFloat64(req message.RequestInterface) message.ReplyInterface {
+	name, raw, strict, err := handler.rawParam(req)
+	if err != nil {
+		return handler.paramFail(req, err)
+	}
+
+	value, err := engine.ToFloat64(raw)
+	if err != nil && strict {
+		return handler.paramFail(req, conversionError(name, "float64", err))
+	}
+
+	param := key_value.New().Set("value", value)
+	return req.Ok(param)
+}
+
+// onInt64 returns an int64 parameter from the Engine.
+//
+// The value is returned as a string to keep the sign and precision in the message.
+func (handler *Handler) onInt64(req message.RequestInterface) message.ReplyInterface {
+	name, raw, strict, err := handler.rawParam(req)
+	if err != nil {
+		return handler.paramFail(req, err)
+	}
+
+	value, err := engine.ToInt64(raw)
+	if err != nil && strict {
+		return handler.paramFail(req, conversionError(name, "int64", err))
+	}
+
+	param := key_value.New().Set("value", strconv.FormatInt(value, 10))
+	return req.Ok(param)
+}
+
+// onDuration returns a time.Duration parameter from the Engine.
+//
+// The value is returned as a string in the time.Duration format, for example "1m30s".
+func (handler *Handler) onDuration(req message.RequestInterface) message.ReplyInterface {
+	name, raw, strict, err := handler.rawParam(req)
+	if err != nil {
+		return handler.paramFail(req, err)
+	}
+
+	value, err := engine.ToDuration(raw)
+	if err != nil && strict {
+		return handler.paramFail(req, conversionError(name, "duration", err))
+	}
+
+	param := key_value.New().Set("value", value.String())
+	return req.Ok(param)
+}
+
+// onStringSlice returns a list of strings from the Engine.
+// The parameter could be a JSON list or comma separated values.
+func (handler *Handler) onStringSlice(req message.RequestInterface) message.ReplyInterface {
+	name, raw, strict, err := handler.rawParam(req)
+	if err != nil {
+		return handler.paramFail(req, err)
+	}
+
+	value, err := engine.ToStringSlice(raw)
+	if err != nil {
+		if strict {
+			return handler.paramFail(req, conversionError(name, "[]string", err))
+		}
+		value = []string{}
 	}
 
-	value := handler.Engine.GetBool(name)
+	param := key_value.New().Set("value", value)
+	return req.Ok(param)
+}
+
+// onStringMap returns a map of strings from the Engine.
+// The parameter could be a JSON object or comma separated key=value pairs.
+func (handler *Handler) onStringMap(req message.RequestInterface) message.ReplyInterface {
+	name, raw, strict, err := handler.rawParam(req)
+	if err != nil {
+		return handler.paramFail(req, err)
+	}
+
+	value, err := engine.ToStringMap(raw)
+	if err != nil {
+		if strict {
+			return handler.paramFail(req, conversionError(name, "map[string]string", err))
+		}
+		value = map[string]string{}
+	}
 
 	param := key_value.New().Set("value", value)
 	return req.Ok(param)
 }
 
+// SetSecretProvider sets the provider that resolves the secret references of the Engine parameters.
+// The resolved secrets are cached for the ttl, if the ttl is 0, then the secrets are not cached.
+//
+// Returns an error if the Engine doesn't resolve the secrets.
+func (handler *Handler) SetSecretProvider(provider engine.SecretProvider, ttl time.Duration) error {
+	resolver, ok := handler.Engine.(engine.SecretResolver)
+	if !ok {
+		return errors.New(unsupported("secrets"))
+	}
+	if err := resolver.SetSecretProvider(provider, ttl); err != nil {
+		return fmt.Errorf("engine.SetSecretProvider: %w", err)
+	}
+
+	return nil
+}
+
+// redact replaces the resolved secrets in the text before it's logged or replied.
+func (handler *Handler) redact(text string) string {
+	if resolver, ok := handler.Engine.(engine.SecretResolver); ok {
+		return resolver.Redact(text)
+	}
+	return text
+}
+
+// ValidateFlags returns *engine.UnknownFlagsError if any command line flag doesn't match a parameter.
+// The defaults could be set after the handler is created, so the flags are not validated by Start.
+// Call it once the defaults and schemas are registered, and stop the startup if it fails.
+//
+// The engine that doesn't bind the flags has no unknown flags.
+func (handler *Handler) ValidateFlags() error {
+	validator, ok := handler.Engine.(engine.FlagValidator)
+	if !ok {
+		return nil
+	}
+	if err := validator.ValidateFlags(); err != nil {
+		return fmt.Errorf("engine.ValidateFlags: %w", err)
+	}
+
+	return nil
+}
+
+// Start the handler.
+// If the Engine is engine.Dev, then the .env and parameter files are reloaded until the handler is closed.
 func (handler *Handler) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if dev, ok := handler.Engine.(*engine.Dev); ok {
+		err := watch.WatchFiles(ctx, dev, func(err error) {
+			handler.logger.Warn("reloading the files failed", "error", handler.redact(err.Error()))
+		})
+		if err != nil {
+			cancel()
+			return fmt.Errorf("watch.WatchFiles: %w", err)
+		}
+	}
 
 	err := handler.handler.Start()
 	if err != nil {
+		cancel()
 		return fmt.Errorf("handler.Start: %w", err)
 	}
+	handler.cancel = cancel
+
+	return nil
+}
+
+// Close the handler and stop reloading the files.
+func (handler *Handler) Close() error {
+	if handler.cancel != nil {
+		handler.cancel()
+		handler.cancel = nil
+	}
+
+	managerClient, err := manager_client.New(SocketConfig())
+	if err != nil {
+		return fmt.Errorf("manager_client.New: %w", err)
+	}
+	if err := managerClient.Close(); err != nil {
+		return fmt.Errorf("managerClient.Close: %w", err)
+	}
 
 	return nil
 }
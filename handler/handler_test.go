@@ -4,15 +4,16 @@ import (
 	"fmt"
 	"github.com/ahmetson/client-lib"
 	"github.com/ahmetson/config-lib/app"
+	"github.com/ahmetson/config-lib/engine"
 	"github.com/ahmetson/config-lib/service"
 	"github.com/ahmetson/datatype-lib/message"
 	handlerConfig "github.com/ahmetson/handler-lib/config"
-	"github.com/ahmetson/handler-lib/manager_client"
 	"github.com/ahmetson/log-lib"
 	"github.com/ahmetson/os-lib/path"
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -77,15 +78,17 @@ func (test *TestHandlerSuite) SetupTest() {
 	test.handler.Engine.Set("bool", true)
 	test.handler.Engine.Set("string", "hello world")
 	test.handler.Engine.Set("uint64", uint64(123))
+	test.handler.Engine.Set("float64", "75.321")
+	test.handler.Engine.Set("int64", "-5")
+	test.handler.Engine.Set("duration", "1m30s")
+	test.handler.Engine.Set("string_slice", "a, b")
+	test.handler.Engine.Set("string_map", `{"a": "1"}`)
 }
 
 func (test *TestHandlerSuite) TearDownTest() {
 	s := test.Require
 
-	managerClient, err := manager_client.New(SocketConfig())
-	s().NoError(err)
-	err = managerClient.Close()
-	s().NoError(err)
+	s().NoError(test.handler.Close())
 
 	s().NoError(test.client.Close())
 
@@ -121,6 +124,20 @@ func (test *TestHandlerSuite) deleteYaml(dir string, name string) {
 
 	filePath := filepath.Join(dir, name+".yml")
 
+	// the backups are written by app.Write
+	backups, err := app.Backups(filePath)
+	s().NoError(err)
+	for _, backupPath := range backups {
+		s().NoError(os.Remove(backupPath))
+	}
+
+	// the lock file is created by app.Write
+	lockExist, err := path.FileExist(app.LockPath(filePath))
+	s().NoError(err)
+	if lockExist {
+		s().NoError(os.Remove(app.LockPath(filePath)))
+	}
+
 	exist, err := path.FileExist(filePath)
 	s().NoError(err)
 
@@ -244,6 +261,46 @@ func (test *TestHandlerSuite) Test_14_GetParam() {
 	valueUint64, err := reply.ReplyParameters().Uint64Value("value")
 	s().NoError(err)
 	s().NotZero(valueUint64)
+
+	req.Command = Float64Param
+	req.Parameters.Set("name", "float64")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	valueFloat64, err := reply.ReplyParameters().Float64Value("value")
+	s().NoError(err)
+	s().Equal(75.321, valueFloat64)
+
+	req.Command = Int64Param
+	req.Parameters.Set("name", "int64")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	valueStr, err = reply.ReplyParameters().StringValue("value")
+	s().NoError(err)
+	s().Equal("-5", valueStr)
+
+	req.Command = DurationParam
+	req.Parameters.Set("name", "duration")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	valueStr, err = reply.ReplyParameters().StringValue("value")
+	s().NoError(err)
+	s().Equal("1m30s", valueStr)
+
+	req.Command = StringSliceParam
+	req.Parameters.Set("name", "string_slice")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	valueSlice, err := reply.ReplyParameters().StringsValue("value")
+	s().NoError(err)
+	s().Equal([]string{"a", "b"}, valueSlice)
+
+	req.Command = StringMapParam
+	req.Parameters.Set("name", "string_map")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	valueMap, err := reply.ReplyParameters().NestedValue("value")
+	s().NoError(err)
+	s().Equal(map[string]string{"a": "1"}, valueMap.MapString())
 }
 
 // Test_15_GenerateHandler set a new service
@@ -292,6 +349,181 @@ func (test *TestHandlerSuite) Test_15_GenerateHandler() {
 	s().NotZero(generatedConfig.Port)
 }
 
+// Test_16_Strict returns an error for the missing or not convertable parameters in the strict mode
+func (test *TestHandlerSuite) Test_16_Strict() {
+	s := test.Require
+
+	// Without the strict mode, the missing parameter returns a zero value
+	req := message.Request{Command: Uint64Param, Parameters: key_value.New()}
+	req.Parameters.Set("name", "not_exist")
+	reply, err := test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+
+	// In the strict mode, the missing parameter fails
+	req.Parameters.Set("strict", true)
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+	s().True(strings.HasPrefix(reply.ErrorMessage(), engine.ErrNotFound.Error()))
+	code, err := reply.ReplyParameters().StringValue("code")
+	s().NoError(err)
+	s().Equal(NotFoundCode, code)
+
+	// In the strict mode, the parameter that can't be converted fails
+	req.Parameters.Set("name", "string")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+	s().True(strings.HasPrefix(reply.ErrorMessage(), engine.ErrConversion.Error()))
+	code, err = reply.ReplyParameters().StringValue("code")
+	s().NoError(err)
+	s().Equal(ConversionCode, code)
+
+	// In the strict mode, the valid parameter is returned
+	req.Parameters.Set("name", "uint64")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+
+	// In the strict mode, the parameter that can't be interpolated fails
+	test.handler.Engine.Set("interpolated", "http://${not_exist}")
+	req = message.Request{Command: StringParam, Parameters: key_value.New()}
+	req.Parameters.Set("name", "interpolated")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+
+	req.Parameters.Set("strict", true)
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+	code, err = reply.ReplyParameters().StringValue("code")
+	s().NoError(err)
+	s().Equal(InterpolationCode, code)
+}
+
+// Test_17_TypedValue restores the Go type of the value by the 'type' route parameter
+func (test *TestHandlerSuite) Test_17_TypedValue() {
+	s := test.Require
+
+	req := message.Request{Command: SetDefaultParam, Parameters: key_value.New()}
+	req.Parameters.Set("name", "typed").Set("value", "5").Set("type", string(engine.Uint64Kind))
+	reply, err := test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	s().Equal(uint64(5), test.handler.Engine.Get("typed"))
+
+	// the value doesn't match the type
+	req.Parameters.Set("value", "-5")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+	s().Contains(reply.ErrorMessage(), engine.ErrConversion.Error())
+
+	req.Parameters.Set("type", "not_a_type")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+
+	// without the type, the value is set as is
+	delete(req.Parameters, "type")
+	req.Parameters.Set("name", "untyped")
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	s().Equal("-5", test.handler.Engine.Get("untyped"))
+}
+
+// Test_18_SetDefaults sets the default parameters at once or none of them
+func (test *TestHandlerSuite) Test_18_SetDefaults() {
+	s := test.Require
+
+	req := message.Request{Command: SetDefaultParams, Parameters: key_value.New()}
+	req.Parameters.Set("params", key_value.New().Set("DB_PORT", "-1").Set("DB_HOST", "localhost"))
+	req.Parameters.Set("types", key_value.New().Set("DB_PORT", string(engine.Uint64Kind)))
+	reply, err := test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	problems, err := reply.ReplyParameters().NestedListValue("problems")
+	s().NoError(err)
+	s().Len(problems, 1)
+	s().Equal("DB_PORT", problems[0]["key"])
+	// none of the parameters is set
+	s().False(test.handler.Engine.Exist("DB_HOST"))
+
+	req.Parameters.Set("params", key_value.New().Set("DB_PORT", "80").Set("DB_HOST", "localhost"))
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	problems, err = reply.ReplyParameters().NestedListValue("problems")
+	s().NoError(err)
+	s().Empty(problems)
+	skipped, err := reply.ReplyParameters().StringsValue("skipped")
+	s().NoError(err)
+	s().Empty(skipped)
+	s().Equal(uint64(80), test.handler.Engine.Get("DB_PORT"))
+	s().Equal("localhost", test.handler.Engine.Get("DB_HOST"))
+
+	// the parameters that are set already keep their values
+	req.Parameters.Set("params", key_value.New().Set("DB_PORT", "90").Set("DB_NAME", "db"))
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	skipped, err = reply.ReplyParameters().StringsValue("skipped")
+	s().NoError(err)
+	s().Equal([]string{"DB_PORT"}, skipped)
+	s().Equal(uint64(80), test.handler.Engine.Get("DB_PORT"))
+	s().Equal("db", test.handler.Engine.Get("DB_NAME"))
+}
+
+// Test_19_Secrets resolves the secret references by the provider of the handler
+func (test *TestHandlerSuite) Test_19_Secrets() {
+	s := test.Require
+
+	test.handler.Engine.Set("db_password", "secret://db/password")
+
+	// without the provider, the reference is not returned
+	req := message.Request{Command: StringParam, Parameters: key_value.New()}
+	req.Parameters.Set("name", "db_password")
+	reply, err := test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	value, err := reply.ReplyParameters().StringValue("value")
+	s().NoError(err)
+	s().Empty(value)
+
+	req.Parameters.Set("strict", true)
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+
+	dir := test.T().TempDir()
+	s().NoError(os.MkdirAll(filepath.Join(dir, "db"), 0700))
+	s().NoError(os.WriteFile(filepath.Join(dir, "db", "password"), []byte("qwerty"), 0600))
+	provider, err := engine.NewDirSecretProvider(dir)
+	s().NoError(err)
+	s().NoError(test.handler.SetSecretProvider(provider, time.Minute))
+
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().True(reply.IsOK())
+	value, err = reply.ReplyParameters().StringValue("value")
+	s().NoError(err)
+	s().Equal("qwerty", value)
+
+	// the resolved secret is redacted in the conversion errors
+	req.Command = Uint64Param
+	reply, err = test.client.Request(&req)
+	s().NoError(err)
+	s().False(reply.IsOK())
+	s().NotContains(reply.ErrorMessage(), "qwerty")
+
+	// the engine without the secrets
+	memoryHandler := &Handler{Engine: engine.NewMemory()}
+	s().Error(memoryHandler.SetSecretProvider(provider, time.Minute))
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestHandler(t *testing.T) {
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
+	handlerConfig "github.com/ahmetson/handler-lib/config"
+	"slices"
+	"strings"
+)
+
+//
+// The custom parameters of the service and its handlers.
+//
+// The parameters are stored in the app configuration with the service.
+// Therefore, two services in the same app could have the same parameter with different values.
+// The parameter names are case-insensitive.
+//
+
+// paramKey returns the key of the parameter in params, or an empty string if it's not set.
+func paramKey(params key_value.KeyValue, name string) string {
+	for key := range params {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
+
+// Param returns the custom parameter of the service.
+// Returns false if the parameter is not set.
+func (s *Service) Param(name string) (interface{}, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	key := paramKey(s.Params, name)
+	if len(key) == 0 {
+		return nil, false
+	}
+	return s.Params[key], true
+}
+
+// SetParam sets the custom parameter of the service.
+// The parameter with the same case-insensitive name is replaced.
+func (s *Service) SetParam(name string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	if s.Params == nil {
+		s.Params = key_value.New()
+	}
+	if key := paramKey(s.Params, name); len(key) > 0 {
+		delete(s.Params, key)
+	}
+	s.Params[name] = value
+}
+
+// HandlerParam returns the custom parameter of the handler.
+// If the handler doesn't have the parameter, then the service parameter is returned.
+// Returns false if neither has the parameter.
+func (s *Service) HandlerParam(handlerId string, name string) (interface{}, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	params := s.HandlerParams[handlerId]
+	if key := paramKey(params, name); len(key) > 0 {
+		return params[key], true
+	}
+	return s.Param(name)
+}
+
+// SetHandlerParam sets the custom parameter of the handler.
+// The handler must be listed in the service.
+func (s *Service) SetHandlerParam(handlerId string, name string, value interface{}) error {
+	if s == nil {
+		return fmt.Errorf("service is nil")
+	}
+
+	if !slices.ContainsFunc(s.Handlers, func(h *handlerConfig.Handler) bool {
+		return h.Id == handlerId
+	}) {
+		return fmt.Errorf("handler('%s') not found in '%s' service", handlerId, s.Id)
+	}
+
+	if s.HandlerParams == nil {
+		s.HandlerParams = make(map[string]key_value.KeyValue)
+	}
+	params := s.HandlerParams[handlerId]
+	if params == nil {
+		params = key_value.New()
+		s.HandlerParams[handlerId] = params
+	}
+	if key := paramKey(params, name); len(key) > 0 {
+		delete(params, key)
+	}
+	params[name] = value
+
+	return nil
+}
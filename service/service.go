@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	clientConfig "github.com/ahmetson/client-lib/config"
+	"github.com/ahmetson/datatype-lib/data_type/key_value"
 	handlerConfig "github.com/ahmetson/handler-lib/config"
 	"slices"
 	"strings"
@@ -35,14 +36,18 @@ type Source struct {
 //   - Handlers that are listed in the service
 //   - Extensions that this service depends on
 //   - Sources that are can access to this service
+//   - Params are the custom parameters of the service
+//   - HandlerParams are the custom parameters of the handlers by the handler id
 type Service struct {
-	Type       Type                     `json:"type" yaml:"type"`
-	Url        string                   `json:"url" yaml:"url"`
-	Id         string                   `json:"id" yaml:"id"`
-	Manager    *clientConfig.Client     `json:"manager" yaml:"manager"`
-	Handlers   []*handlerConfig.Handler `json:"handlers" yaml:"handlers"`
-	Extensions []*clientConfig.Client   `json:"extensions,omitempty" yaml:"extensions,omitempty"`
-	Sources    []*Source                `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Type          Type                          `json:"type" yaml:"type"`
+	Url           string                        `json:"url" yaml:"url"`
+	Id            string                        `json:"id" yaml:"id"`
+	Manager       *clientConfig.Client          `json:"manager" yaml:"manager"`
+	Handlers      []*handlerConfig.Handler      `json:"handlers" yaml:"handlers"`
+	Extensions    []*clientConfig.Client        `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	Sources       []*Source                     `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Params        key_value.KeyValue            `json:"params,omitempty" yaml:"params,omitempty"`
+	HandlerParams map[string]key_value.KeyValue `json:"handler_params,omitempty" yaml:"handler_params,omitempty"`
 }
 
 // ManagerId generates a service manager id.
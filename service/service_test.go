@@ -407,6 +407,41 @@ func (test *TestServiceSuite) Test_17_Service_SourceById() {
 
 }
 
+// Test_18_Service_Params tests the custom parameters of the service and its handlers
+func (test *TestServiceSuite) Test_18_Service_Params() {
+	s := test.Require
+
+	_, ok := test.service.Param("PORT")
+	s().False(ok)
+
+	test.service.SetParam("PORT", 80)
+	value, ok := test.service.Param("port")
+	s().True(ok)
+	s().Equal(80, value)
+
+	// the parameter names are case-insensitive
+	test.service.SetParam("port", 90)
+	s().Len(test.service.Params, 1)
+	value, _ = test.service.Param("PORT")
+	s().Equal(90, value)
+
+	// the handler must be in the service
+	err := test.service.SetHandlerParam(test.handlerOfCategory.Id, "PORT", 100)
+	s().Error(err)
+	test.service.SetHandler(test.handlerOfCategory)
+	s().NoError(test.service.SetHandlerParam(test.handlerOfCategory.Id, "PORT", 100))
+
+	// the handler parameter is preferred, otherwise the service parameter is used
+	value, ok = test.service.HandlerParam(test.handlerOfCategory.Id, "port")
+	s().True(ok)
+	s().Equal(100, value)
+	value, ok = test.service.HandlerParam("not_exist", "port")
+	s().True(ok)
+	s().Equal(90, value)
+	_, ok = test.service.HandlerParam(test.handlerOfCategory.Id, "HOST")
+	s().False(ok)
+}
+
 // Usage:
 // go test ./service  -v --coverprofile ./service-test-cover.txt
 // go tool cover -html="./service-test-cover.txt"
@@ -0,0 +1,82 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"github.com/ahmetson/config-lib/engine"
+	"github.com/fsnotify/fsnotify"
+	"path/filepath"
+	"slices"
+)
+
+// WatchFiles reloads the .env and parameter files of the engine when any of them is changed.
+// The changed parameters are passed to the engine.Dev.Watch handlers.
+//
+// It doesn't block, watching stops when the context is canceled.
+// If the reload fails, for example the file is being edited, then the handleErr is called,
+// and the reload is tried again on the next change.
+func WatchFiles(ctx context.Context, config *engine.Dev, handleErr func(error)) error {
+	if config == nil {
+		return fmt.Errorf("config is nil")
+	}
+
+	filePaths := append(config.EnvFiles(), config.ParamFiles()...)
+	if len(filePaths) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("fsnotify.NewWatcher: %w", err)
+	}
+
+	// the editors replace the files, so the directories are watched
+	dirs := make([]string, 0, len(filePaths))
+	for i, filePath := range filePaths {
+		filePaths[i] = filepath.Clean(filePath)
+		dir := filepath.Dir(filePaths[i])
+		if slices.Contains(dirs, dir) {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			closeErr := watcher.Close()
+			if closeErr != nil {
+				return fmt.Errorf("%v: watcher.Close: %w", err, closeErr)
+			}
+			return fmt.Errorf("watcher.Add('%s'): %w", dir, err)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	go func() {
+		defer func() {
+			_ = watcher.Close()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if handleErr != nil {
+					handleErr(fmt.Errorf("watcher: %w", err))
+				}
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod || !slices.Contains(filePaths, filepath.Clean(event.Name)) {
+					continue
+				}
+				if err := config.Reload(); err != nil && handleErr != nil {
+					handleErr(fmt.Errorf("config.Reload: %w", err))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
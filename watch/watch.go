@@ -21,7 +21,7 @@ import (
 //
 // Watch could be called only once. If it's already called, then it will skip it without an error.
 //
-// For production, we could call config.viper.WatchRemoteConfig() for example in etcd.
+// For the remote key-value stores, for example etcd, use engine.Dev.AddBackend.
 func Watch(config *engine.Dev, watchHandle func(interface{}, error)) error {
 	if config.HandleChange != nil {
 		return nil
@@ -0,0 +1,72 @@
+package watch
+
+import (
+	"context"
+	"github.com/ahmetson/config-lib/engine"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing orchestra
+type TestWatchSuite struct {
+	suite.Suite
+	args    []string
+	envPath string
+	dev     *engine.Dev
+	changes chan engine.Change
+}
+
+func (test *TestWatchSuite) SetupTest() {
+	s := test.Require
+
+	test.args = os.Args
+	test.envPath = filepath.Join(test.T().TempDir(), ".env")
+	s().NoError(os.WriteFile(test.envPath, []byte("WATCH_HOST=a\n"), 0600))
+
+	os.Args = []string{test.args[0], test.envPath}
+	dev, err := engine.NewDev()
+	s().NoError(err)
+	test.dev = dev
+
+	test.changes = make(chan engine.Change, 10)
+	test.dev.Watch(func(change engine.Change) {
+		test.changes <- change
+	})
+}
+
+func (test *TestWatchSuite) TearDownTest() {
+	os.Args = test.args
+	test.Require().NoError(os.Unsetenv("WATCH_HOST"))
+}
+
+// Test_10_WatchFiles tests the reload on the file changes
+func (test *TestWatchSuite) Test_10_WatchFiles() {
+	s := test.Require
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s().Error(WatchFiles(ctx, nil, nil))
+	s().NoError(WatchFiles(ctx, test.dev, nil))
+
+	s().NoError(os.WriteFile(test.envPath, []byte("WATCH_HOST=b\n"), 0600))
+	select {
+	case change := <-test.changes:
+		s().Equal("WATCH_HOST", change.Name)
+		s().Equal("b", change.Value)
+	case <-time.After(time.Second * 2):
+		s().Fail("the change is not received")
+	}
+	s().Equal("b", test.dev.GetString("WATCH_HOST"))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestWatch(t *testing.T) {
+	suite.Run(t, new(TestWatchSuite))
+}